@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+
+	"example/internal/service"
+
+	"go.uber.org/zap"
+)
+
+func cacheWarm(a *app, args []string) error {
+	flags := flag.NewFlagSet("cache warm", flag.ExitOnError)
+	recent := flags.Int("recent", 0, "only warm the N users who logged in most recently (0 warms all users)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cacheService := service.NewCacheService(a.userRepo, a.cacheManager)
+	warmed, err := cacheService.WarmUsers(*recent)
+	if err != nil {
+		return err
+	}
+
+	a.log.Info("Cache warmed", zap.Int("users", warmed))
+	return nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"example/internal/config"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/internal/service"
+	"example/pkg/cache"
+	"example/pkg/database"
+	"example/pkg/logger"
+	"example/pkg/redis"
+	"example/pkg/storage"
+
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// command is an admin subcommand such as "cache warm"
+type command struct {
+	usage string
+	run   func(app *app, args []string) error
+}
+
+var commands = map[string]command{
+	"cache warm": {
+		usage: "Pre-populate the user cache: cache warm [-recent N]",
+		run:   cacheWarm,
+	},
+	"users purge": {
+		usage: "Permanently remove expired soft-deleted users: users purge [-retention 720h]",
+		run:   usersPurge,
+	},
+	"users import": {
+		usage: "Create users from a CSV or NDJSON file: users import -file users.csv [-format csv|ndjson] [-dry-run]",
+		run:   usersImport,
+	},
+	"users export": {
+		usage: "Write users to a file: users export -out users.csv [-format csv|ndjson|json] [-fields id,email] [-name N] [-email E]",
+		run:   usersExport,
+	},
+	"users erase": {
+		usage: "Permanently anonymize a user, deleted or not: users erase -user ID",
+		run:   usersErase,
+	},
+	"users verify-erasures": {
+		usage: "Check that no erasure receipt was altered or removed: users verify-erasures",
+		run:   usersVerifyErasures,
+	},
+	"users unlock": {
+		usage: "Lift the lock put on an account after failed logins: users unlock -user ID",
+		run:   usersUnlock,
+	},
+	"users set-status": {
+		usage: "Suspend, disable or reactivate a user: users set-status -user ID -status active|suspended|disabled [-reason R]",
+		run:   usersSetStatus,
+	},
+	"users grant-role": {
+		usage: "Grant a role to a user: users grant-role -user ID -role admin|support",
+		run:   usersGrantRole,
+	},
+	"users revoke-role": {
+		usage: "Revoke a role from a user: users revoke-role -user ID -role admin|support",
+		run:   usersRevokeRole,
+	},
+	"users email-collisions": {
+		usage: "Report active users sharing an email identity: users email-collisions",
+		run:   usersEmailCollisions,
+	},
+	"users normalize-emails": {
+		usage: "Rewrite stored emails to their normalized form: users normalize-emails",
+		run:   usersNormalizeEmails,
+	},
+}
+
+// cliActor is recorded in the audit log as the author of changes made by
+// admin commands
+var cliActor = model.AuditActor{UserAgent: "admin-cli"}
+
+// app holds the dependencies shared by admin commands
+type app struct {
+	cfg          *config.Config
+	log          *zap.Logger
+	db           *gorm.DB
+	redisClient  goredis.UniversalClient
+	cacheKeys    cache.KeyBuilder
+	cacheManager cache.Manager
+	userRepo     repository.UserRepository
+	roleRepo     repository.RoleRepository
+}
+
+func main() {
+	name, args, ok := findCommand(os.Args[1:])
+	if !ok {
+		printUsage()
+		os.Exit(2)
+	}
+
+	// Initialize logger
+	log, err := logger.Initialize("development")
+	if err != nil {
+		panic("Failed to initialize logger: " + err.Error())
+	}
+	defer log.Sync()
+
+	a, err := newApp(log)
+	if err != nil {
+		log.Fatal("Cannot initialize admin command", zap.Error(err))
+	}
+
+	if err := commands[name].run(a, args); err != nil {
+		log.Fatal("Command failed", zap.String("command", name), zap.Error(err))
+	}
+}
+
+// findCommand matches the leading arguments against the known commands
+func findCommand(args []string) (string, []string, bool) {
+	for n := len(args); n > 0; n-- {
+		name := strings.Join(args[:n], " ")
+		if _, ok := commands[name]; ok {
+			return name, args[n:], true
+		}
+	}
+	return "", nil, false
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: admin <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-20s %s\n", name, commands[name].usage)
+	}
+}
+
+// userService builds the user service from the app's configuration
+func (a *app) userService() service.UserService {
+	return service.NewUserService(
+		a.userRepo,
+		a.roleRepo,
+		service.NewPasswordPolicy(&a.cfg.Auth),
+		service.NewEmailNormalizer(&a.cfg.Users),
+	)
+}
+
+// roleService builds the role service from the app's repositories
+func (a *app) roleService() service.RoleService {
+	return service.NewRoleService(a.roleRepo, a.userRepo)
+}
+
+// loginLockoutService builds the login lockout service from the app's
+// configuration
+func (a *app) loginLockoutService() service.LoginLockoutService {
+	return service.NewLoginLockoutService(
+		a.redisClient,
+		nil,
+		a.cacheKeys,
+		service.NewEmailNormalizer(&a.cfg.Users),
+		a.userRepo,
+		service.NewAuditService(repository.NewAuditRepository(a.db)),
+		&a.cfg.Auth,
+	)
+}
+
+// privacyService builds the privacy service from the app's configuration
+func (a *app) privacyService() (service.PrivacyService, error) {
+	blobStore, err := storage.NewBlobStore(&a.cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("blob storage: %w", err)
+	}
+
+	return service.NewPrivacyService(
+		a.userRepo,
+		a.roleRepo,
+		repository.NewOrganizationRepository(a.db, a.cacheManager),
+		repository.NewPasswordResetRepository(a.db),
+		repository.NewErasureReceiptRepository(a.db),
+		repository.NewAuditRepository(a.db),
+		blobStore,
+		&a.cfg.Auth,
+	), nil
+}
+
+func newApp(log *zap.Logger) (*app, error) {
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	// Initialize database
+	db, err := database.NewPostgresDB(&cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+
+	// Initialize Redis; admin commands don't run in degraded mode
+	redisClient, err := redis.NewRedisClient(&cfg.Redis)
+	if err != nil {
+		return nil, fmt.Errorf("connect to Redis: %w", err)
+	}
+
+	// Initialize cache manager
+	cacheTTLs, err := cache.NewTTLPolicy(cache.TTLOptions{
+		Default:  cfg.Cache.DefaultTTL,
+		Prefixes: cfg.Cache.TTLs,
+		Jitter:   cfg.Cache.TTLJitter,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cache TTL configuration: %w", err)
+	}
+	cacheKeys := cache.NewKeyBuilder(cfg.App.Name, cfg.Cache.SchemaVersion)
+	cacheManager := cache.NewCacheManager(redisClient, cacheKeys, cacheTTLs)
+
+	return &app{
+		cfg:          cfg,
+		log:          log,
+		db:           db,
+		redisClient:  redisClient,
+		cacheKeys:    cacheKeys,
+		cacheManager: cacheManager,
+		userRepo:     repository.NewUserRepository(db, cacheManager),
+		roleRepo:     repository.NewRoleRepository(db, cacheManager),
+	}, nil
+}
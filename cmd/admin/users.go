@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"example/internal/http/handler/requests"
+	"example/internal/job"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/internal/service"
+
+	"go.uber.org/zap"
+)
+
+func usersPurge(a *app, args []string) error {
+	flags := flag.NewFlagSet("users purge", flag.ExitOnError)
+	retention := flags.Duration("retention", a.cfg.Users.DeletedRetention, "purge users soft deleted longer ago than this")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := a.cfg.Users
+	cfg.DeletedRetention = *retention
+
+	purged, err := job.NewUserPurge(a.userService(), &cfg).RunOnce()
+	if err != nil {
+		return err
+	}
+
+	a.log.Info("Deleted users purged", zap.Int64("purged", purged))
+	return nil
+}
+
+// usersEmailCollisions lists active users whose emails only differ in case,
+// or in what the provider rules ignore when USERS_EMAIL_PROVIDER_RULES is
+// set. They must be resolved before the lower(email) unique index migration
+// can run.
+func usersEmailCollisions(a *app, args []string) error {
+	flags := flag.NewFlagSet("users email-collisions", flag.ExitOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	collisions, err := a.userService().EmailCollisions()
+	if err != nil {
+		return err
+	}
+	if err := printEmailCollisions(collisions); err != nil {
+		return err
+	}
+
+	a.log.Info("Email collisions found", zap.Int("collisions", len(collisions)))
+	return nil
+}
+
+// usersNormalizeEmails rewrites the stored emails to the form logins and
+// lookups use, which is needed after enabling USERS_EMAIL_PROVIDER_RULES.
+// Users sharing an identity are left alone and printed like
+// email-collisions does.
+func usersNormalizeEmails(a *app, args []string) error {
+	flags := flag.NewFlagSet("users normalize-emails", flag.ExitOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	result, err := a.userService().NormalizeEmails(cliActor)
+	if err != nil {
+		return err
+	}
+	if err := printEmailCollisions(result.Collisions); err != nil {
+		return err
+	}
+
+	a.log.Info("User emails normalized",
+		zap.Int("normalized", result.Normalized),
+		zap.Int("collisions", len(result.Collisions)),
+	)
+	return nil
+}
+
+func printEmailCollisions(collisions []service.EmailCollision) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "IDENTITY\tID\tEMAIL\tCREATED AT")
+	for _, collision := range collisions {
+		for _, user := range collision.Users {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", collision.Key, user.ID, user.Email, user.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+	}
+	return w.Flush()
+}
+
+// usersUnlock lifts the lock of an account locked after too many failed
+// logins
+func usersUnlock(a *app, args []string) error {
+	flags := flag.NewFlagSet("users unlock", flag.ExitOnError)
+	userID := flags.Uint("user", 0, "ID of the user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *userID == 0 {
+		return fmt.Errorf("-user is required")
+	}
+
+	if err := a.loginLockoutService().Unlock(*userID, cliActor); err != nil {
+		return err
+	}
+
+	a.log.Info("User unlocked", zap.Uint("user_id", *userID))
+	return nil
+}
+
+// usersSetStatus suspends, disables or reactivates a user from the command
+// line
+func usersSetStatus(a *app, args []string) error {
+	flags := flag.NewFlagSet("users set-status", flag.ExitOnError)
+	userID := flags.Uint("user", 0, "ID of the user")
+	status := flags.String("status", "", "New status: active, suspended or disabled")
+	reason := flags.String("reason", "", "Why the status is changed, required unless reactivating")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *userID == 0 {
+		return fmt.Errorf("-user is required")
+	}
+
+	user, err := a.userService().SetStatus(*userID, model.UserStatus(*status), *reason, cliActor)
+	if err != nil {
+		return err
+	}
+
+	a.log.Info("User status changed", zap.Uint("user_id", user.ID), zap.String("status", string(user.Status)))
+	return nil
+}
+
+// usersGrantRole grants a role from the command line, which is how the first
+// admin is created
+func usersGrantRole(a *app, args []string) error {
+	userID, role, err := parseRoleFlags("users grant-role", args)
+	if err != nil {
+		return err
+	}
+
+	roles, err := a.roleService().Grant(userID, role, cliActor)
+	if err != nil {
+		return err
+	}
+
+	a.log.Info("Role granted", zap.Uint("user_id", userID), zap.Any("roles", roles))
+	return nil
+}
+
+func usersRevokeRole(a *app, args []string) error {
+	userID, role, err := parseRoleFlags("users revoke-role", args)
+	if err != nil {
+		return err
+	}
+
+	roles, err := a.roleService().Revoke(userID, role, cliActor)
+	if err != nil {
+		return err
+	}
+
+	a.log.Info("Role revoked", zap.Uint("user_id", userID), zap.Any("roles", roles))
+	return nil
+}
+
+func parseRoleFlags(name string, args []string) (uint, model.Role, error) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	userID := flags.Uint("user", 0, "ID of the user")
+	role := flags.String("role", "", "role to change: admin or support")
+	if err := flags.Parse(args); err != nil {
+		return 0, "", err
+	}
+	if *userID == 0 || *role == "" {
+		return 0, "", fmt.Errorf("-user and -role are required")
+	}
+
+	return *userID, model.Role(*role), nil
+}
+
+// usersImport creates users from a file with the same validation as the API's
+// import endpoint, printing the rows that failed
+func usersImport(a *app, args []string) error {
+	flags := flag.NewFlagSet("users import", flag.ExitOnError)
+	path := flags.String("file", "", "CSV or NDJSON file to import")
+	format := flags.String("format", "", "file format, csv or ndjson; defaults to the file extension")
+	dryRun := flags.Bool("dry-run", false, "only validate the rows")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return fmt.Errorf("-file is required")
+	}
+	if *format == "" {
+		*format = importFormatFromPath(*path)
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	source, err := requests.NewUserImportSource(file, *format)
+	if err != nil {
+		return err
+	}
+
+	importService := service.NewUserImportService(
+		a.userRepo,
+		service.NewPasswordPolicy(&a.cfg.Auth),
+		service.NewEmailNormalizer(&a.cfg.Users),
+		&a.cfg.Users,
+	)
+	report, err := importService.Import(context.Background(), source, *dryRun, cliActor)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tEMAIL\tERROR")
+	for _, row := range report.Rows {
+		if row.Status != service.ImportRowFailed {
+			continue
+		}
+		message := row.Error
+		for _, field := range row.Fields {
+			message += fmt.Sprintf("; %s: %s %s", field.Field, field.Tag, field.Value)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", row.Line, row.Email, message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	a.log.Info("Users imported",
+		zap.Bool("dry_run", report.DryRun),
+		zap.Int("total", report.Total),
+		zap.Int("created", report.Created),
+		zap.Int("valid", report.Valid),
+		zap.Int("failed", report.Failed),
+		zap.Bool("truncated", report.Truncated),
+	)
+	return nil
+}
+
+// importFormatFromPath guesses the import format from the file extension
+func importFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return requests.UserImportFormatNDJSON
+	default:
+		return requests.UserImportFormatCSV
+	}
+}
+
+// usersExport writes the users to a file in the same formats as the API's
+// export endpoint. The file is written next to its destination and only
+// renamed once complete, so a failed export never leaves a truncated file.
+func usersExport(a *app, args []string) error {
+	flags := flag.NewFlagSet("users export", flag.ExitOnError)
+	path := flags.String("out", "", "file to write")
+	format := flags.String("format", "", "file format, csv, ndjson or json; defaults to the file extension")
+	fields := flags.String("fields", "", "comma separated fields to export, defaults to all")
+	name := flags.String("name", "", "only users whose name contains this")
+	email := flags.String("email", "", "only users whose email contains this")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return fmt.Errorf("-out is required")
+	}
+	if *format == "" {
+		*format = exportFormatFromPath(*path)
+	}
+
+	opts := service.UserExportOptions{
+		Format: *format,
+		Fields: requests.SplitFields(*fields),
+		Filter: repository.UserListFilter{Name: *name, Email: *email},
+	}
+	exportService := service.NewUserExportService(a.userRepo)
+	if err := exportService.Validate(opts); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(*path), filepath.Base(*path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	exported, err := exportService.Export(context.Background(), file, opts)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(file.Name(), *path); err != nil {
+		return err
+	}
+
+	a.log.Info("Users exported", zap.Int("exported", exported), zap.String("file", *path))
+	return nil
+}
+
+// exportFormatFromPath guesses the export format from the file extension
+func exportFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return service.UserExportFormatNDJSON
+	case ".json":
+		return service.UserExportFormatJSON
+	default:
+		return service.UserExportFormatCSV
+	}
+}
+
+// usersErase erases a user on their behalf, such as when the request came in
+// by mail, and prints the receipt
+func usersErase(a *app, args []string) error {
+	flags := flag.NewFlagSet("users erase", flag.ExitOnError)
+	userID := flags.Uint("user", 0, "ID of the user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *userID == 0 {
+		return fmt.Errorf("-user is required")
+	}
+
+	privacyService, err := a.privacyService()
+	if err != nil {
+		return err
+	}
+	receipt, err := privacyService.Erase(*userID, cliActor)
+	if err != nil {
+		return err
+	}
+
+	a.log.Info("User erased",
+		zap.Uint("user_id", receipt.UserID),
+		zap.Uint("receipt_id", receipt.ID),
+		zap.String("hash", receipt.Hash),
+	)
+	return nil
+}
+
+// usersVerifyErasures recomputes the hash chain of the erasure receipts and
+// fails on the first receipt that was altered, or follows a removed one
+func usersVerifyErasures(a *app, args []string) error {
+	privacyService, err := a.privacyService()
+	if err != nil {
+		return err
+	}
+
+	checked, err := privacyService.VerifyErasureReceipts()
+	if err != nil {
+		return err
+	}
+
+	a.log.Info("Erasure receipts verified", zap.Int("receipts", checked))
+	return nil
+}
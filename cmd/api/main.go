@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"example/internal/config"
 	"example/internal/http/handler"
+	"example/internal/job"
+	"example/internal/policy"
 	"example/internal/repository"
 	"example/internal/router"
 	"example/internal/service"
 	"example/pkg/cache"
 	"example/pkg/database"
 	"example/pkg/logger"
+	"example/pkg/mailer"
 	"example/pkg/redis"
+	"example/pkg/storage"
 
 	_ "example/docs" // Import swagger docs
 
@@ -43,29 +48,118 @@ func main() {
 	}
 
 	// Initialize Redis
-	redisClient, err := redis.NewRedisClient(&cfg.Redis)
-	if err != nil {
-		log.Fatal("Cannot connect to Redis", zap.Error(err))
+	redisClient, redisErr := redis.NewRedisClient(&cfg.Redis)
+	if redisErr != nil {
+		// A nil client means the configuration itself is broken
+		if redisClient == nil || cfg.Cache.Required {
+			log.Fatal("Cannot connect to Redis", zap.Error(redisErr))
+		}
+		log.Warn("Cannot connect to Redis, starting in degraded mode without cache", zap.Error(redisErr))
 	}
 
-	// Initialize cache manager
-	cacheManager := cache.NewCacheManager(redisClient)
+	// Initialize cache manager behind a circuit breaker
+	cacheTTLs, err := cache.NewTTLPolicy(cache.TTLOptions{
+		Default:  cfg.Cache.DefaultTTL,
+		Prefixes: cfg.Cache.TTLs,
+		Jitter:   cfg.Cache.TTLJitter,
+	})
+	if err != nil {
+		log.Fatal("Invalid cache TTL configuration", zap.Error(err))
+	}
+	cacheKeys := cache.NewKeyBuilder(cfg.App.Name, cfg.Cache.SchemaVersion)
+	cacheManager := cache.NewCircuitBreaker(
+		cache.NewCacheManager(redisClient, cacheKeys, cacheTTLs),
+		cache.BreakerOptions{
+			Threshold:     cfg.Cache.BreakerThreshold,
+			ProbeInterval: cfg.Cache.BreakerProbeInterval,
+		},
+	)
+	if redisErr != nil {
+		cacheManager.Trip()
+	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db, cacheManager)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	roleRepo := repository.NewRoleRepository(db, cacheManager)
+	organizationRepo := repository.NewOrganizationRepository(db, cacheManager)
+	erasureReceiptRepo := repository.NewErasureReceiptRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+
+	// Initialize mailer
+	mail, err := mailer.NewMailer(&cfg.Mail)
+	if err != nil {
+		log.Fatal("Invalid mail configuration", zap.Error(err))
+	}
+
+	// Initialize blob storage
+	blobStore, err := storage.NewBlobStore(&cfg.Storage)
+	if err != nil {
+		log.Fatal("Invalid storage configuration", zap.Error(err))
+	}
 
 	// Initialize services
-	userService := service.NewUserService(userRepo)
+	passwordPolicy := service.NewPasswordPolicy(&cfg.Auth)
+	emailNormalizer := service.NewEmailNormalizer(&cfg.Users)
+	userService := service.NewUserService(userRepo, roleRepo, passwordPolicy, emailNormalizer)
+	passwordResetService := service.NewPasswordResetService(userRepo, passwordResetRepo, mail, passwordPolicy, emailNormalizer, cacheManager, &cfg.Auth, cfg.App.URL)
+	emailVerificationService := service.NewEmailVerificationService(userRepo, mail, emailNormalizer, &cfg.Auth, cfg.App.URL)
+	avatarService := service.NewAvatarService(userRepo, blobStore, &cfg.Users)
+	cacheService := service.NewCacheService(userRepo, cacheManager)
+	roleService := service.NewRoleService(roleRepo, userRepo)
+	userImportService := service.NewUserImportService(userRepo, passwordPolicy, emailNormalizer, &cfg.Users)
+	userExportService := service.NewUserExportService(userRepo)
+	organizationService := service.NewOrganizationService(organizationRepo, userRepo, mail, emailNormalizer, cacheManager, &cfg.Users, cfg.App.URL)
+	auditService := service.NewAuditService(auditRepo)
+	loginLockoutService := service.NewLoginLockoutService(redisClient, cacheManager, cacheKeys, emailNormalizer, userRepo, auditService, &cfg.Auth)
+	privacyService := service.NewPrivacyService(userRepo, roleRepo, organizationRepo, passwordResetRepo, erasureReceiptRepo, auditRepo, blobStore, &cfg.Auth)
+
+	// Start background jobs
+	go job.NewUserPurge(userService, &cfg.Users).Run(context.Background())
 
 	// Initialize handlers
-	userHandler := handler.NewUserHandler(userService)
-	authHandler, err := handler.NewAuthHandler(userService, &cfg.Auth)
+	userHandler := handler.NewUserHandler(userService, emailVerificationService)
+	healthHandler := handler.NewHealthHandler(db, cacheManager)
+	cacheHandler := handler.NewCacheHandler(cacheService)
+	passwordResetHandler := handler.NewPasswordResetHandler(passwordResetService)
+	emailVerificationHandler := handler.NewEmailVerificationHandler(emailVerificationService)
+	avatarHandler := handler.NewAvatarHandler(avatarService)
+	roleHandler := handler.NewRoleHandler(roleService)
+	organizationHandler := handler.NewOrganizationHandler(organizationService)
+	userImportHandler := handler.NewUserImportHandler(userImportService)
+	userExportHandler := handler.NewUserExportHandler(userExportService)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	loginLockoutHandler := handler.NewLoginLockoutHandler(loginLockoutService)
+	authHandler, err := handler.NewAuthHandler(userService, roleService, organizationService, auditService, loginLockoutService, &cfg.Auth)
 	if err != nil {
 		log.Fatal("Cannot initialize auth handler", zap.Error(err))
 	}
 
 	// Initialize and start router
-	r := router.SetupRouter(userHandler, authHandler)
+	r := router.SetupRouter(
+		userHandler,
+		authHandler,
+		healthHandler,
+		cacheHandler,
+		passwordResetHandler,
+		emailVerificationHandler,
+		avatarHandler,
+		roleHandler,
+		organizationHandler,
+		userImportHandler,
+		userExportHandler,
+		privacyHandler,
+		auditHandler,
+		loginLockoutHandler,
+		policy.NewRoutes(&cfg.Users),
+		service.NewPasswordResetIPLimiter(cacheManager, &cfg.Auth),
+	)
+
+	// Blobs stored on the local filesystem are served by the API itself
+	if cfg.Storage.Driver == config.StorageDriverLocal || cfg.Storage.Driver == "" {
+		r.Static("/media", cfg.Storage.LocalDir)
+	}
 
 	// Start server
 	log.Info("Starting server", zap.String("port", cfg.App.Port))
@@ -3,4 +3,7 @@ package config
 type AppConfig struct {
 	Name string `mapstructure:"APP_NAME"`
 	Port string `mapstructure:"APP_PORT"`
+	// URL is the public address of the frontend, used to build links sent by
+	// email
+	URL string `mapstructure:"APP_URL"`
 }
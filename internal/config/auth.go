@@ -1,8 +1,70 @@
 package config
 
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// legacyAuthKeys maps the auth settings to the names they had before taking
+// the AUTH_ prefix of the other settings
+var legacyAuthKeys = map[string]string{
+	"AUTH_SECRET_KEY":        "secret_key",
+	"AUTH_REALM":             "realm",
+	"AUTH_TIMEOUT_HOURS":     "timeout_hours",
+	"AUTH_MAX_REFRESH_HOURS": "max_refresh_hours",
+}
+
+// applyLegacyAuthKeys reads the auth settings still set under their old name,
+// so existing deployments keep their JWT settings. The new name wins when
+// both are set.
+func applyLegacyAuthKeys(v *viper.Viper) {
+	for key, legacy := range legacyAuthKeys {
+		if !v.IsSet(key) && v.IsSet(legacy) {
+			v.Set(key, v.Get(legacy))
+		}
+	}
+}
+
 type AuthConfig struct {
-	SecretKey  string `mapstructure:"secret_key"`
-	Realm      string `mapstructure:"realm" default:"api"`
-	Timeout    int    `mapstructure:"timeout_hours" default:"24"`
-	MaxRefresh int    `mapstructure:"max_refresh_hours" default:"24"`
+	SecretKey  string `mapstructure:"AUTH_SECRET_KEY"`
+	Realm      string `mapstructure:"AUTH_REALM" default:"api"`
+	Timeout    int    `mapstructure:"AUTH_TIMEOUT_HOURS" default:"24"`
+	MaxRefresh int    `mapstructure:"AUTH_MAX_REFRESH_HOURS" default:"24"`
+	// ErasureEmailKey keys the email hashes of erasure receipts. Changing it
+	// keeps the receipts valid but stops earlier ones from being matched to
+	// an email. When empty a key is derived from SecretKey.
+	ErasureEmailKey string `mapstructure:"AUTH_ERASURE_EMAIL_KEY"`
+
+	PasswordMinLength     int  `mapstructure:"AUTH_PASSWORD_MIN_LENGTH" default:"8"`
+	PasswordRequireUpper  bool `mapstructure:"AUTH_PASSWORD_REQUIRE_UPPER" default:"true"`
+	PasswordRequireLower  bool `mapstructure:"AUTH_PASSWORD_REQUIRE_LOWER" default:"true"`
+	PasswordRequireDigit  bool `mapstructure:"AUTH_PASSWORD_REQUIRE_DIGIT" default:"true"`
+	PasswordRequireSymbol bool `mapstructure:"AUTH_PASSWORD_REQUIRE_SYMBOL" default:"false"`
+
+	// PasswordResetTTL is how long a password reset link stays valid
+	PasswordResetTTL time.Duration `mapstructure:"AUTH_PASSWORD_RESET_TTL" default:"1h"`
+	// A client IP may request PasswordResetIPLimit resets, and an email may
+	// be sent PasswordResetEmailLimit links, per PasswordResetRateWindow
+	PasswordResetIPLimit    int           `mapstructure:"AUTH_PASSWORD_RESET_IP_LIMIT" default:"10"`
+	PasswordResetEmailLimit int           `mapstructure:"AUTH_PASSWORD_RESET_EMAIL_LIMIT" default:"3"`
+	PasswordResetRateWindow time.Duration `mapstructure:"AUTH_PASSWORD_RESET_RATE_WINDOW" default:"1h"`
+
+	// RequireEmailVerification refuses logins until the user confirmed their
+	// email
+	RequireEmailVerification bool `mapstructure:"AUTH_REQUIRE_EMAIL_VERIFICATION" default:"false"`
+	// EmailVerificationTTL is how long an email verification link stays valid
+	EmailVerificationTTL time.Duration `mapstructure:"AUTH_EMAIL_VERIFICATION_TTL" default:"48h"`
+
+	// Failed logins are counted per account and per client IP over
+	// LockoutWindow. Reaching a threshold locks the account or IP for
+	// LockoutDuration, doubled on every further lock up to
+	// LockoutMaxDuration. The doubling starts over once there was no lock for
+	// LockoutResetAfter.
+	LockoutAccountThreshold int           `mapstructure:"AUTH_LOCKOUT_ACCOUNT_THRESHOLD" default:"5"`
+	LockoutIPThreshold      int           `mapstructure:"AUTH_LOCKOUT_IP_THRESHOLD" default:"20"`
+	LockoutWindow           time.Duration `mapstructure:"AUTH_LOCKOUT_WINDOW" default:"15m"`
+	LockoutDuration         time.Duration `mapstructure:"AUTH_LOCKOUT_DURATION" default:"1m"`
+	LockoutMaxDuration      time.Duration `mapstructure:"AUTH_LOCKOUT_MAX_DURATION" default:"1h"`
+	LockoutResetAfter       time.Duration `mapstructure:"AUTH_LOCKOUT_RESET_AFTER" default:"24h"`
 }
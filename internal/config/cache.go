@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+type CacheConfig struct {
+	SchemaVersion int `mapstructure:"CACHE_SCHEMA_VERSION" default:"1"`
+	// Required makes startup fail when Redis can't be reached instead of
+	// running with the cache bypassed
+	Required             bool          `mapstructure:"CACHE_REQUIRED" default:"false"`
+	BreakerThreshold     int           `mapstructure:"CACHE_BREAKER_THRESHOLD" default:"5"`
+	BreakerProbeInterval time.Duration `mapstructure:"CACHE_BREAKER_PROBE_INTERVAL" default:"10s"`
+
+	DefaultTTL time.Duration `mapstructure:"CACHE_DEFAULT_TTL" default:"1h"`
+	// TTLs overrides DefaultTTL per key prefix as comma separated
+	// prefix=duration pairs, e.g. "user=1h,user:email=30m". The longest
+	// matching prefix wins.
+	TTLs []string `mapstructure:"CACHE_TTLS" default:""`
+	// TTLJitter is the fraction of a TTL, between 0 and 1, that is randomly
+	// added or removed on every write
+	TTLJitter float64 `mapstructure:"CACHE_TTL_JITTER" default:"0.1"`
+}
@@ -8,7 +8,11 @@ type Config struct {
 	App      AppConfig      `mapstructure:",squash"`
 	Database DatabaseConfig `mapstructure:",squash"`
 	Redis    RedisConfig    `mapstructure:",squash"`
+	Cache    CacheConfig    `mapstructure:",squash"`
 	Auth     AuthConfig     `mapstructure:",squash"`
+	Users    UsersConfig    `mapstructure:",squash"`
+	Mail     MailConfig     `mapstructure:",squash"`
+	Storage  StorageConfig  `mapstructure:",squash"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -19,6 +23,8 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	applyLegacyAuthKeys(viper.GetViper())
+
 	config := &Config{}
 	err = viper.Unmarshal(config)
 	if err != nil {
@@ -0,0 +1,23 @@
+package config
+
+// Mail drivers
+const (
+	MailDriverSMTP = "smtp"
+	MailDriverLog  = "log"
+	MailDriverFile = "file"
+)
+
+type MailConfig struct {
+	// Driver is smtp, log or file. log and file don't deliver anything, so
+	// emails can be read locally without a mail provider.
+	Driver string `mapstructure:"MAIL_DRIVER" default:"log"`
+	From   string `mapstructure:"MAIL_FROM"`
+
+	SMTPHost     string `mapstructure:"MAIL_SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"MAIL_SMTP_PORT" default:"587"`
+	SMTPUsername string `mapstructure:"MAIL_SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"MAIL_SMTP_PASSWORD"`
+
+	// FilePath is the file the file driver appends emails to
+	FilePath string `mapstructure:"MAIL_FILE_PATH" default:"tmp/mail.log"`
+}
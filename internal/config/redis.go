@@ -1,8 +1,32 @@
 package config
 
+// Redis deployment modes supported by RedisConfig.Mode
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
 type RedisConfig struct {
 	Host     string `mapstructure:"REDIS_HOST" default:"localhost"`
 	Port     string `mapstructure:"REDIS_PORT" default:"6379"`
+	Username string `mapstructure:"REDIS_USERNAME" default:""`
 	Password string `mapstructure:"REDIS_PASSWORD" default:""`
 	DB       int    `mapstructure:"REDIS_DB" default:"0"`
+
+	// Mode is one of standalone, sentinel or cluster
+	Mode string `mapstructure:"REDIS_MODE" default:"standalone"`
+	// Addrs lists sentinel or cluster node addresses as comma separated
+	// host:port pairs. When empty, Host and Port are used.
+	Addrs            []string `mapstructure:"REDIS_ADDRS" default:""`
+	MasterName       string   `mapstructure:"REDIS_MASTER_NAME" default:""`
+	SentinelUsername string   `mapstructure:"REDIS_SENTINEL_USERNAME" default:""`
+	SentinelPassword string   `mapstructure:"REDIS_SENTINEL_PASSWORD" default:""`
+
+	TLSEnabled            bool   `mapstructure:"REDIS_TLS_ENABLED" default:"false"`
+	TLSCAFile             string `mapstructure:"REDIS_TLS_CA_FILE" default:""`
+	TLSCertFile           string `mapstructure:"REDIS_TLS_CERT_FILE" default:""`
+	TLSKeyFile            string `mapstructure:"REDIS_TLS_KEY_FILE" default:""`
+	TLSServerName         string `mapstructure:"REDIS_TLS_SERVER_NAME" default:""`
+	TLSInsecureSkipVerify bool   `mapstructure:"REDIS_TLS_INSECURE_SKIP_VERIFY" default:"false"`
 }
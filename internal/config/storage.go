@@ -0,0 +1,28 @@
+package config
+
+// Blob storage drivers
+const (
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+type StorageConfig struct {
+	// Driver is local or s3. s3 works with any S3-compatible service such
+	// as MinIO.
+	Driver string `mapstructure:"STORAGE_DRIVER" default:"local"`
+	// PublicURL is the base URL blobs are served from. For the local driver
+	// the API serves them itself under /media.
+	PublicURL string `mapstructure:"STORAGE_PUBLIC_URL"`
+
+	// LocalDir is where the local driver writes blobs
+	LocalDir string `mapstructure:"STORAGE_LOCAL_DIR" default:"tmp/media"`
+
+	S3Endpoint  string `mapstructure:"STORAGE_S3_ENDPOINT"`
+	S3Region    string `mapstructure:"STORAGE_S3_REGION" default:"us-east-1"`
+	S3Bucket    string `mapstructure:"STORAGE_S3_BUCKET"`
+	S3AccessKey string `mapstructure:"STORAGE_S3_ACCESS_KEY"`
+	S3SecretKey string `mapstructure:"STORAGE_S3_SECRET_KEY"`
+	// S3PathStyle puts the bucket in the path instead of the host name, as
+	// MinIO expects by default
+	S3PathStyle bool `mapstructure:"STORAGE_S3_PATH_STYLE" default:"false"`
+}
@@ -0,0 +1,36 @@
+package config
+
+import "time"
+
+type UsersConfig struct {
+	// DeletedRetention is how long soft-deleted users are kept before they
+	// are purged for good
+	DeletedRetention time.Duration `mapstructure:"USERS_DELETED_RETENTION" default:"720h"`
+	// PurgeInterval is how often the API purges expired users; 0 disables
+	// the background job
+	PurgeInterval time.Duration `mapstructure:"USERS_PURGE_INTERVAL" default:"24h"`
+	// EmailProviderRules normalizes emails of well-known providers, e.g.
+	// ignoring dots and +tags in Gmail addresses, so they can't be used to
+	// register several accounts for one mailbox. Emails are looked up in
+	// their normalized form, so after enabling it run
+	// `admin users normalize-emails` to rewrite the stored ones.
+	EmailProviderRules bool `mapstructure:"USERS_EMAIL_PROVIDER_RULES" default:"false"`
+	// AvatarMaxBytes is the largest avatar upload accepted
+	AvatarMaxBytes int64 `mapstructure:"USERS_AVATAR_MAX_BYTES" default:"5242880"`
+	// PublicProfiles lets users see the public profile of other users, their
+	// name and avatar. When false other users are reported as not found.
+	PublicProfiles bool `mapstructure:"USERS_PUBLIC_PROFILES" default:"false"`
+	// ImportWorkers is how many passwords are hashed concurrently during an
+	// import; 0 uses one worker per CPU
+	ImportWorkers int `mapstructure:"USERS_IMPORT_WORKERS" default:"0"`
+	// ImportBatchSize is how many imported users are inserted per statement
+	ImportBatchSize int `mapstructure:"USERS_IMPORT_BATCH_SIZE" default:"500"`
+	// ImportMaxRows is the most rows a single import reads
+	ImportMaxRows int `mapstructure:"USERS_IMPORT_MAX_ROWS" default:"10000"`
+	// ImportMaxBytes is the largest import file accepted
+	ImportMaxBytes int64 `mapstructure:"USERS_IMPORT_MAX_BYTES" default:"33554432"`
+	// A member may send InviteLimit organization invitations per
+	// InviteRateWindow
+	InviteLimit      int           `mapstructure:"USERS_INVITE_LIMIT" default:"50"`
+	InviteRateWindow time.Duration `mapstructure:"USERS_INVITE_RATE_WINDOW" default:"1h"`
+}
@@ -1,38 +1,77 @@
 package handler
 
 import (
+	"errors"
 	"example/internal/config"
 	"example/internal/http/handler/requests"
 	"example/internal/model"
 	"example/internal/service"
+	"example/pkg/logger"
+	"net/http"
+	"strconv"
 	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // AuthHandler defines the interface for authentication handler operations
 type AuthHandler interface {
 	Middleware() *jwt.GinJWTMiddleware
+	Refresh(c *gin.Context)
 }
 
+var (
+	errSessionRevoked  = errors.New("password was changed, please log in again")
+	errSessionUserGone = errors.New("user no longer exists")
+	errSessionUnknown  = errors.New("session could not be verified")
+	errSessionOrgGone  = errors.New("organization membership was removed, please log in again")
+)
+
+// session is what the authenticator hands to payloadFunc: the user and the
+// organization the token is issued for, 0 for none
+type session struct {
+	user           *model.User
+	organizationID uint
+}
+
+// Why a login failed, as recorded in the audit log
+const (
+	loginFailureCredentials   = "invalid_credentials"
+	loginFailureUnverified    = "email_not_verified"
+	loginFailureOrganization  = "organization_unavailable"
+	loginFailureLocked        = "locked"
+	loginFailureInactive      = "account_inactive"
+	loginFailureUnknownReason = "error"
+)
+
 type authHandler struct {
-	userService    service.UserService
-	authMiddleware *jwt.GinJWTMiddleware
+	userService         service.UserService
+	organizationService service.OrganizationService
+	auditService        service.AuditService
+	authMiddleware      *jwt.GinJWTMiddleware
 }
 
-func NewAuthHandler(userService service.UserService, cfg *config.AuthConfig) (AuthHandler, error) {
+func NewAuthHandler(
+	userService service.UserService,
+	roleService service.RoleService,
+	organizationService service.OrganizationService,
+	auditService service.AuditService,
+	lockoutService service.LoginLockoutService,
+	cfg *config.AuthConfig,
+) (AuthHandler, error) {
 	authMiddleware, err := jwt.New(&jwt.GinJWTMiddleware{
 		Realm:           cfg.Realm,
 		Key:             []byte(cfg.SecretKey),
 		Timeout:         24 * time.Hour,
 		MaxRefresh:      72 * time.Hour,
 		IdentityKey:     identityKey,
-		PayloadFunc:     payloadFunc,
+		PayloadFunc:     payloadFunc(roleService),
 		IdentityHandler: identityHandler,
-		Authenticator:   authenticator(userService),
-		Authorizator:    authorizator,
+		Authenticator:   authenticator(userService, organizationService, auditService, lockoutService, cfg),
+		Authorizator:    authorizator(userService, roleService, organizationService),
 		Unauthorized:    unauthorized,
 		TokenLookup:     "header: Authorization, query: token",
 		TokenHeadName:   "Bearer",
@@ -44,59 +83,332 @@ func NewAuthHandler(userService service.UserService, cfg *config.AuthConfig) (Au
 	}
 
 	return &authHandler{
-		userService:    userService,
-		authMiddleware: authMiddleware,
+		userService:         userService,
+		organizationService: organizationService,
+		auditService:        auditService,
+		authMiddleware:      authMiddleware,
 	}, nil
 }
 
-func payloadFunc(data interface{}) jwt.MapClaims {
-	if v, ok := data.(*model.User); ok {
-		return jwt.MapClaims{
-			"id":    v.ID,
-			"email": v.Email,
+// payloadFunc builds the token claims. The roles claim tells clients what the
+// user could do when they logged in; access is always checked against the
+// stored roles, so it is left out rather than failing the login when they
+// can't be loaded. org_id is the organization the token acts in, omitted for
+// users without one.
+func payloadFunc(roleService service.RoleService) func(interface{}) jwt.MapClaims {
+	return func(data interface{}) jwt.MapClaims {
+		s, ok := data.(*session)
+		if !ok {
+			return jwt.MapClaims{}
+		}
+		v := s.user
+
+		claims := jwt.MapClaims{
+			"id":     v.ID,
+			"email":  v.Email,
+			"pwd_at": v.PasswordChangedUnix(),
+		}
+		if s.organizationID != 0 {
+			claims["org_id"] = s.organizationID
+		}
+		roles, err := roleService.Roles(v.ID)
+		if err != nil {
+			logger.GetLogger().Error("Failed to load roles for token", zap.Uint("user_id", v.ID), zap.Error(err))
+			return claims
 		}
+		claims["roles"] = roles
+		return claims
 	}
-	return jwt.MapClaims{}
 }
 
 func identityHandler(c *gin.Context) interface{} {
 	claims := jwt.ExtractClaims(c)
-	return &model.User{
+	user := &model.User{
 		Model: gorm.Model{
 			ID: uint(claims["id"].(float64)),
 		},
 		Email: claims["email"].(string),
 	}
+	if changedAt := passwordChangedAt(claims); changedAt > 0 {
+		t := time.Unix(changedAt, 0)
+		user.PasswordChangedAt = &t
+	}
+	return user
+}
+
+// passwordChangedAt returns the pwd_at claim, 0 for tokens issued before the
+// claim existed
+func passwordChangedAt(claims jwt.MapClaims) int64 {
+	v, _ := claims["pwd_at"].(float64)
+	return int64(v)
+}
+
+// organizationID returns the org_id claim, 0 for tokens without an
+// organization
+func organizationID(claims jwt.MapClaims) uint {
+	v, _ := claims["org_id"].(float64)
+	return uint(v)
+}
+
+// checkSession reports why a token for the given user and password change time
+// is no longer accepted, or nil if it still is. The status is checked first,
+// through its own short-lived cache entry, so suspending a user takes effect
+// within seconds even if evicting their cached user failed.
+func checkSession(userService service.UserService, id uint, pwdAt int64) error {
+	status, err := userService.Status(id)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return errSessionUserGone
+		}
+		return errSessionUnknown
+	}
+	if err := statusError(status); err != nil {
+		return err
+	}
+
+	user, err := userService.GetUser(id)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return errSessionUserGone
+		}
+		return errSessionUnknown
+	}
+
+	if pwdAt < user.PasswordChangedUnix() {
+		return errSessionRevoked
+	}
+
+	return nil
+}
+
+// statusError returns why a user with the status may not log in, or nil if
+// they may
+func statusError(status model.UserStatus) error {
+	switch status {
+	case model.UserStatusSuspended:
+		return service.ErrAccountSuspended
+	case model.UserStatusDisabled:
+		return service.ErrAccountDisabled
+	}
+	return nil
+}
+
+// checkOrganization returns the membership the token acts in, nil for tokens
+// without an organization, or why the token is no longer accepted
+func checkOrganization(organizationService service.OrganizationService, userID, orgID uint) (*model.Membership, error) {
+	if orgID == 0 {
+		return nil, nil
+	}
+
+	membership, err := organizationService.ActiveMembership(orgID, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrMemberNotFound) {
+			return nil, errSessionOrgGone
+		}
+		return nil, errSessionUnknown
+	}
+	return membership, nil
 }
 
-func authenticator(userService service.UserService) func(*gin.Context) (interface{}, error) {
+// authenticator checks the credentials and records every login attempt with
+// well-formed credentials in the audit log. Wrong passwords count towards
+// locking the account and the client IP; while either is locked every attempt
+// is refused the same way, without checking the password.
+func authenticator(
+	userService service.UserService,
+	organizationService service.OrganizationService,
+	auditService service.AuditService,
+	lockoutService service.LoginLockoutService,
+	cfg *config.AuthConfig,
+) func(*gin.Context) (interface{}, error) {
 	return func(c *gin.Context) (interface{}, error) {
 		var loginReq requests.LoginRequest
 		if err := c.ShouldBindJSON(&loginReq); err != nil {
 			return nil, jwt.ErrMissingLoginValues
 		}
 
+		if err := lockoutService.Check(loginReq.Email, c.ClientIP()); err != nil {
+			recordLoginFailure(c, userService, auditService, loginReq.Email, loginFailureLocked)
+			c.Set(authErrorKey, err)
+			return nil, err
+		}
+
+		// Storage errors are reported as a failed login too, gin-jwt would
+		// send their text to the client
 		user, err := userService.Login(loginReq.Email, loginReq.Password)
 		if err != nil {
+			reason := loginFailureUnknownReason
+			if errors.Is(err, service.ErrInvalidCredentials) {
+				reason = loginFailureCredentials
+				lockoutService.Failure(loginReq.Email, auditActor(c))
+			}
+			recordLoginFailure(c, userService, auditService, loginReq.Email, reason)
 			return nil, jwt.ErrFailedAuthentication
 		}
+		lockoutService.Success(loginReq.Email)
 
-		if user == nil {
-			return nil, jwt.ErrFailedAuthentication
+		// Like verification, only checked once the password matched
+		if err := statusError(user.Status); err != nil {
+			recordLoginFailure(c, userService, auditService, loginReq.Email, loginFailureInactive)
+			return nil, err
+		}
+
+		// Only checked once the password matched, so it doesn't reveal
+		// which emails have an account
+		if cfg.RequireEmailVerification && !user.EmailVerified() {
+			recordLoginFailure(c, userService, auditService, loginReq.Email, loginFailureUnverified)
+			return nil, service.ErrEmailNotVerified
 		}
 
-		return user, nil
+		orgID, err := loginOrganization(organizationService, user.ID, loginReq.OrganizationID)
+		if err != nil {
+			recordLoginFailure(c, userService, auditService, loginReq.Email, loginFailureOrganization)
+			return nil, err
+		}
+
+		// The login goes ahead even if it can't be recorded, it only
+		// orders the cache warmup
+		if err := userService.RecordLogin(user.ID); err != nil {
+			logger.GetLogger().Error("Failed to record login", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+
+		event := model.NewAuditEvent(model.AuditLoginSucceeded, sessionActor(c, user.ID), user.ID)
+		if orgID != 0 {
+			event.Metadata = model.AuditMetadata{"organization_id": strconv.FormatUint(uint64(orgID), 10)}
+		}
+		auditService.Record(event)
+
+		return &session{user: user, organizationID: orgID}, nil
+	}
+}
+
+// recordLoginFailure records a failed login for the email. The attempt is
+// attributed to the user with that email, if there is one, so failures can be
+// looked up by target. The email itself isn't recorded: the audit log is kept
+// after erasure, and most failures name emails without an account.
+func recordLoginFailure(c *gin.Context, userService service.UserService, auditService service.AuditService, email, reason string) {
+	var targetID uint
+	if user, err := userService.GetUserByEmail(email); err == nil && user != nil {
+		targetID = user.ID
+	}
+
+	event := model.NewAuditEvent(model.AuditLoginFailed, auditActor(c), targetID)
+	event.Metadata = model.AuditMetadata{"reason": reason}
+	auditService.Record(event)
+}
+
+// sessionActor is the actor of a request made on behalf of the user whose
+// session it is, before the JWT middleware identified them
+func sessionActor(c *gin.Context, userID uint) model.AuditActor {
+	actor := auditActor(c)
+	actor.UserID = &userID
+	return actor
+}
+
+// loginOrganization picks the organization a new token acts in: the requested
+// one, which the user must be an active member of, or else the one they
+// joined first
+func loginOrganization(organizationService service.OrganizationService, userID uint, requested *uint) (uint, error) {
+	if requested == nil {
+		orgID, err := organizationService.DefaultOrganization(userID)
+		if err != nil {
+			return 0, jwt.ErrFailedAuthentication
+		}
+		return orgID, nil
 	}
+
+	if _, err := organizationService.ActiveMembership(*requested, userID); err != nil {
+		if errors.Is(err, service.ErrMemberNotFound) {
+			return 0, service.ErrOrganizationNotFound
+		}
+		return 0, jwt.ErrFailedAuthentication
+	}
+	return *requested, nil
 }
 
-func authorizator(data interface{}, c *gin.Context) bool {
-	return true // Authorization is now handled by route-specific middleware
+// authorizator rejects tokens issued before the user's last password change,
+// tokens of users that no longer exist or are suspended or disabled and tokens
+// for an organization the user was removed from. It loads the user's current
+// roles for RequireRole and RequirePermission, so a revoked role takes effect
+// on the next request rather than when the token expires.
+func authorizator(
+	userService service.UserService,
+	roleService service.RoleService,
+	organizationService service.OrganizationService,
+) func(interface{}, *gin.Context) bool {
+	return func(data interface{}, c *gin.Context) bool {
+		user, ok := data.(*model.User)
+		if !ok {
+			return false
+		}
+
+		if err := checkSession(userService, user.ID, user.PasswordChangedUnix()); err != nil {
+			c.Set(authErrorKey, err)
+			return false
+		}
+
+		roles, err := roleService.Roles(user.ID)
+		if err != nil {
+			c.Set(authErrorKey, errSessionUnknown)
+			return false
+		}
+		c.Set(rolesKey, roles)
+
+		membership, err := checkOrganization(organizationService, user.ID, organizationID(jwt.ExtractClaims(c)))
+		if err != nil {
+			c.Set(authErrorKey, err)
+			return false
+		}
+		if membership != nil {
+			c.Set(membershipKey, membership)
+		}
+
+		return true
+	}
 }
 
 func unauthorized(c *gin.Context, code int, message string) {
+	// gin-jwt answers a failed authorizator with 403, but a revoked session
+	// means the client has to log in again. A locked login is answered with
+	// 429 and when to retry.
+	if err, ok := c.Get(authErrorKey); ok {
+		var tooMany *service.TooManyRequestsError
+		if errors.As(err.(error), &tooMany) {
+			NewServiceErrorResponse(c, tooMany)
+			return
+		}
+		code = http.StatusUnauthorized
+		message = err.(error).Error()
+	}
 	NewErrorResponse(c, code, "Unauthorized", []interface{}{message})
 }
 
+// Refresh issues a new token for a still valid session. gin-jwt copies the
+// claims of the old token without consulting the authorizator, so revoked
+// sessions are rejected here first.
+func (h *authHandler) Refresh(c *gin.Context) {
+	claims, err := h.authMiddleware.CheckIfTokenExpire(c)
+	if err != nil {
+		unauthorized(c, http.StatusUnauthorized, h.authMiddleware.HTTPStatusMessageFunc(err, c))
+		return
+	}
+
+	id, _ := claims["id"].(float64)
+	if err := checkSession(h.userService, uint(id), passwordChangedAt(jwt.MapClaims(claims))); err != nil {
+		unauthorized(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if _, err := checkOrganization(h.organizationService, uint(id), organizationID(jwt.MapClaims(claims))); err != nil {
+		unauthorized(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	h.authMiddleware.RefreshHandler(c)
+	if c.Writer.Status() == http.StatusOK {
+		h.auditService.Record(model.NewAuditEvent(model.AuditTokenRefreshed, sessionActor(c, uint(id)), uint(id)))
+	}
+}
+
 func (h *authHandler) Middleware() *jwt.GinJWTMiddleware {
 	return h.authMiddleware
 }
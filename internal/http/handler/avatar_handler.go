@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"example/internal/http/handler/responses"
+	"example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is allowed on top of the avatar size for the multipart
+// boundaries and headers
+const multipartOverhead = 64 << 10
+
+// AvatarHandler defines the interface for avatar handler operations
+type AvatarHandler interface {
+	Upload(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type avatarHandler struct {
+	service service.AvatarService
+}
+
+func NewAvatarHandler(service service.AvatarService) AvatarHandler {
+	return &avatarHandler{
+		service: service,
+	}
+}
+
+// Upload godoc
+// @Summary Upload avatar
+// @Description Set the avatar of the currently logged in user. The image is cropped to a square and stored at several sizes, whose URLs are returned in avatar_urls. PNG, JPEG, GIF and WebP images are accepted, whatever their declared content type.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} BaseResponse{data=responses.UserResponse} "Avatar uploaded successfully"
+// @Failure 400 {object} BaseResponse "Missing file or unsupported image"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 413 {object} BaseResponse "Avatar too large"
+// @Failure 503 {object} BaseResponse "Avatar storage unavailable"
+// @Router /users/me/avatar [put]
+func (h *avatarHandler) Upload(c *gin.Context) {
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.service.MaxBytes()+multipartOverhead)
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			NewErrorResponse(c, http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge), []interface{}{"avatar is too large"})
+			return
+		}
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{"send the image in the avatar form field"})
+		return
+	}
+	if header.Size > h.service.MaxBytes() {
+		NewErrorResponse(c, http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge), []interface{}{"avatar is too large"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+	defer file.Close()
+
+	user, err := h.service.Upload(authenticatedUser.ID, file, auditActor(c))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	c.Header("ETag", userETag(user.Version))
+	NewSuccessResponse(c, http.StatusOK, "Avatar uploaded successfully", responses.UserResponseFromModel(user))
+}
+
+// Delete godoc
+// @Summary Delete avatar
+// @Description Remove the avatar of the currently logged in user
+// @Tags users
+// @Produce json
+// @Success 200 {object} BaseResponse{data=responses.UserResponse} "Avatar deleted successfully"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Router /users/me/avatar [delete]
+func (h *avatarHandler) Delete(c *gin.Context) {
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.Delete(authenticatedUser.ID, auditActor(c))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	c.Header("ETag", userETag(user.Version))
+	NewSuccessResponse(c, http.StatusOK, "Avatar deleted successfully", responses.UserResponseFromModel(user))
+}
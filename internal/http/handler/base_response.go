@@ -1,15 +1,28 @@
 package handler
 
 import (
+	"net/http"
+
+	"example/pkg/validator"
+
 	"github.com/gin-gonic/gin"
 )
 
 type BaseResponse struct {
-	Success bool          `json:"success"`
-	Status  int           `json:"status,omitempty"`
-	Message string        `json:"message,omitempty"`
-	Data    interface{}   `json:"data,omitempty"`
-	Errors  []interface{} `json:"errors,omitempty"`
+	Success    bool          `json:"success"`
+	Status     int           `json:"status,omitempty"`
+	Message    string        `json:"message,omitempty"`
+	Data       interface{}   `json:"data,omitempty"`
+	Pagination *Pagination   `json:"pagination,omitempty"`
+	Errors     []interface{} `json:"errors,omitempty"`
+}
+
+// Pagination describes where a paginated list continues
+type Pagination struct {
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiY3JlYXRlZF9hdCIsInYiOiIyMDI0LTAxLTAxVDEwOjAwOjAwWiIsImlkIjoyMH0"`
+	HasMore    bool   `json:"has_more" example:"true"`
+	Limit      int    `json:"limit" example:"20"`
+	Total      *int64 `json:"total,omitempty" example:"42"`
 }
 
 func NewSuccessResponse(c *gin.Context, statusCode int, message string, data interface{}) {
@@ -21,6 +34,16 @@ func NewSuccessResponse(c *gin.Context, statusCode int, message string, data int
 	})
 }
 
+func NewPaginatedResponse(c *gin.Context, statusCode int, message string, data interface{}, pagination *Pagination) {
+	c.JSON(statusCode, BaseResponse{
+		Success:    true,
+		Status:     statusCode,
+		Message:    message,
+		Data:       data,
+		Pagination: pagination,
+	})
+}
+
 func NewErrorResponse(c *gin.Context, statusCode int, message string, errors []interface{}) {
 	c.JSON(statusCode, BaseResponse{
 		Success: false,
@@ -30,3 +53,11 @@ func NewErrorResponse(c *gin.Context, statusCode int, message string, errors []i
 	})
 
 }
+
+func NewValidationErrorResponse(c *gin.Context, validationErrs []validator.ValidationError) {
+	errs := make([]interface{}, len(validationErrs))
+	for i, v := range validationErrs {
+		errs[i] = v
+	}
+	NewErrorResponse(c, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errs)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"net/http"
+
+	"example/internal/http/handler/requests"
+	"example/internal/http/handler/responses"
+	"example/internal/service"
+	"example/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheHandler defines the interface for cache administration handler operations
+type CacheHandler interface {
+	Warmup(c *gin.Context)
+	Get(c *gin.Context)
+	GetTTL(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type cacheHandler struct {
+	service service.CacheService
+}
+
+func NewCacheHandler(service service.CacheService) CacheHandler {
+	return &cacheHandler{
+		service: service,
+	}
+}
+
+// Warmup godoc
+// @Summary Warm the user cache
+// @Description Pre-populate the user:<id> and user:email:<email> cache entries for all users, or only the ones who logged in most recently
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body requests.CacheWarmupRequest false "Warmup options"
+// @Success 200 {object} BaseResponse{data=responses.CacheWarmupResponse} "Cache warmed successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Failure 500 {object} BaseResponse "Internal server error"
+// @Router /admin/cache/warmup [post]
+func (h *cacheHandler) Warmup(c *gin.Context) {
+	var req requests.CacheWarmupRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+			return
+		}
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	warmed, err := h.service.WarmUsers(req.Recent)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Cache warmed successfully", responses.CacheWarmupResponse{Warmed: warmed})
+}
+
+// Get godoc
+// @Summary Look up a cache entry
+// @Description Get the cached value and remaining TTL of a key, given without its namespace. Sensitive fields are redacted.
+// @Tags admin
+// @Produce json
+// @Param key path string true "Cache key, e.g. user:1"
+// @Success 200 {object} BaseResponse{data=responses.CacheEntryResponse} "Cache entry retrieved successfully"
+// @Failure 404 {object} BaseResponse "Cache entry not found"
+// @Failure 503 {object} BaseResponse "Cache unavailable"
+// @Router /admin/cache/keys/{key} [get]
+func (h *cacheHandler) Get(c *gin.Context) {
+	entry, err := h.service.Lookup(c.Param("key"))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Cache entry retrieved successfully", responses.CacheEntryResponseFromEntry(entry))
+}
+
+// GetTTL godoc
+// @Summary Get the TTL of a cache entry
+// @Description Get the remaining time to live of a key, given without its namespace
+// @Tags admin
+// @Produce json
+// @Param key path string true "Cache key, e.g. user:1"
+// @Success 200 {object} BaseResponse{data=responses.CacheTTLResponse} "Cache TTL retrieved successfully"
+// @Failure 404 {object} BaseResponse "Cache entry not found"
+// @Failure 503 {object} BaseResponse "Cache unavailable"
+// @Router /admin/cache/keys/{key}/ttl [get]
+func (h *cacheHandler) GetTTL(c *gin.Context) {
+	key := c.Param("key")
+	ttl, err := h.service.TTL(key)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Cache TTL retrieved successfully", responses.CacheTTLResponseFromDuration(key, ttl))
+}
+
+// Delete godoc
+// @Summary Evict a cache entry
+// @Description Remove a key, given without its namespace, from the cache
+// @Tags admin
+// @Produce json
+// @Param key path string true "Cache key, e.g. user:1"
+// @Success 200 {object} BaseResponse "Cache entry evicted successfully"
+// @Failure 503 {object} BaseResponse "Cache unavailable"
+// @Router /admin/cache/keys/{key} [delete]
+func (h *cacheHandler) Delete(c *gin.Context) {
+	if err := h.service.Evict(c.Param("key")); err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Cache entry evicted successfully", nil)
+}
@@ -2,4 +2,13 @@ package handler
 
 const (
 	identityKey = "id"
+	// authErrorKey holds the reason the authorizator rejected a token
+	authErrorKey = "auth_error"
+	// rolesKey holds the authenticated user's current roles
+	rolesKey = "roles"
+	// accessKey holds the policy.Access granted for the requested resource
+	accessKey = "access"
+	// membershipKey holds the membership of the organization the token acts
+	// in, unset for tokens without an organization
+	membershipKey = "membership"
 )
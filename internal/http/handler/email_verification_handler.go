@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+
+	"example/internal/http/handler/requests"
+	"example/internal/http/handler/responses"
+	"example/internal/service"
+	"example/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerificationHandler defines the interface for email verification handler operations
+type EmailVerificationHandler interface {
+	Confirm(c *gin.Context)
+	Resend(c *gin.Context)
+}
+
+type emailVerificationHandler struct {
+	service service.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(service service.EmailVerificationService) EmailVerificationHandler {
+	return &emailVerificationHandler{
+		service: service,
+	}
+}
+
+// Confirm godoc
+// @Summary Confirm email address
+// @Description Mark the user's email as verified using the token from the link sent on signup or after an email change
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.EmailConfirmRequest true "Verification token"
+// @Success 200 {object} BaseResponse{data=responses.UserResponse} "Email verified successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload or invalid or expired token"
+// @Failure 500 {object} BaseResponse "Internal server error"
+// @Router /auth/email/confirm [post]
+func (h *emailVerificationHandler) Confirm(c *gin.Context) {
+	var req requests.EmailConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	user, err := h.service.Confirm(req.Token, auditActor(c))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Email verified successfully", responses.UserResponseFromModel(user))
+}
+
+// Resend godoc
+// @Summary Resend verification email
+// @Description Send a new verification link. The response is the same whether or not the email belongs to an unverified account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.EmailResendRequest true "Account email"
+// @Success 202 {object} BaseResponse "Verification link sent if the account exists and isn't verified"
+// @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Router /auth/email/resend [post]
+func (h *emailVerificationHandler) Resend(c *gin.Context) {
+	var req requests.EmailResendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	// Failures are logged by the service but not reported, as they would
+	// reveal that the account exists
+	_ = h.service.Resend(req.Email)
+
+	NewSuccessResponse(c, http.StatusAccepted, "If an unverified account exists for this email, a verification link has been sent", nil)
+}
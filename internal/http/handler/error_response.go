@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"example/internal/service"
+	"example/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// NewServiceErrorResponse responds to an error returned by a service, picking
+// the status code from its domain error type. Only the domain message is sent;
+// anything else is logged and answered with a bare 500, so storage errors never
+// reach the client.
+func NewServiceErrorResponse(c *gin.Context, err error) {
+	var (
+		notFound     *service.NotFoundError
+		conflict     *service.ConflictError
+		validation   *service.ValidationError
+		unauthorized *service.UnauthorizedError
+		forbidden    *service.ForbiddenError
+		unavailable  *service.UnavailableError
+		tooMany      *service.TooManyRequestsError
+	)
+
+	switch {
+	case errors.As(err, &validation):
+		if len(validation.Fields) > 0 {
+			NewValidationErrorResponse(c, validation.Fields)
+			return
+		}
+		NewErrorResponse(c, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), []interface{}{validation.Message})
+	case errors.As(err, &notFound):
+		NewErrorResponse(c, http.StatusNotFound, http.StatusText(http.StatusNotFound), []interface{}{notFound.Message})
+	case errors.As(err, &conflict):
+		NewErrorResponse(c, http.StatusConflict, http.StatusText(http.StatusConflict), []interface{}{conflict.Message})
+	case errors.As(err, &unauthorized):
+		NewErrorResponse(c, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized), []interface{}{unauthorized.Message})
+	case errors.As(err, &forbidden):
+		NewErrorResponse(c, http.StatusForbidden, http.StatusText(http.StatusForbidden), []interface{}{forbidden.Message})
+	case errors.As(err, &unavailable):
+		NewErrorResponse(c, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable), []interface{}{unavailable.Message})
+	case errors.As(err, &tooMany):
+		if tooMany.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(tooMany.RetryAfter.Seconds()))))
+		}
+		NewErrorResponse(c, http.StatusTooManyRequests, http.StatusText(http.StatusTooManyRequests), []interface{}{tooMany.Message})
+	default:
+		logger.GetLogger().Error("Request failed",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.FullPath()),
+			zap.Error(err),
+		)
+		NewErrorResponse(c, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), nil)
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"example/internal/http/handler/responses"
+	"example/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+// HealthHandler defines the interface for health check operations
+type HealthHandler interface {
+	Check(c *gin.Context)
+}
+
+type healthHandler struct {
+	db    *gorm.DB
+	cache cache.StateReporter
+}
+
+func NewHealthHandler(db *gorm.DB, cache cache.StateReporter) HealthHandler {
+	return &healthHandler{
+		db:    db,
+		cache: cache,
+	}
+}
+
+// Check godoc
+// @Summary Health check
+// @Description Report the health of the API and its dependencies. The API is degraded, but still serving, while the cache is bypassed.
+// @Tags health
+// @Produce json
+// @Success 200 {object} BaseResponse{data=responses.HealthResponse} "Service is healthy or degraded"
+// @Failure 503 {object} BaseResponse{data=responses.HealthResponse} "Service is unavailable"
+// @Router /health [get]
+func (h *healthHandler) Check(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	cacheState := h.cache.State()
+	response := responses.HealthResponse{
+		Status:   "ok",
+		Database: "up",
+		Cache:    string(cacheState),
+	}
+
+	if cacheState != cache.StateClosed {
+		response.Status = "degraded"
+	}
+
+	sqlDB, err := h.db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		response.Status = "unavailable"
+		response.Database = "down"
+		c.JSON(http.StatusServiceUnavailable, BaseResponse{
+			Success: false,
+			Status:  http.StatusServiceUnavailable,
+			Message: "Service unavailable",
+			Data:    response,
+		})
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Service is "+response.Status, response)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginLockoutHandler defines the interface for login lockout handler
+// operations
+type LoginLockoutHandler interface {
+	Unlock(c *gin.Context)
+}
+
+type loginLockoutHandler struct {
+	service service.LoginLockoutService
+}
+
+func NewLoginLockoutHandler(service service.LoginLockoutService) LoginLockoutHandler {
+	return &loginLockoutHandler{
+		service: service,
+	}
+}
+
+// Unlock godoc
+// @Summary Unlock a user's account
+// @Description Lift the lock put on a user's account after too many failed logins and forget its failed logins. Locks of client IPs are left alone.
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} BaseResponse "User unlocked successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Failure 503 {object} BaseResponse "Login lockout unavailable"
+// @Router /admin/users/{id}/unlock [post]
+func (h *loginLockoutHandler) Unlock(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Unlock(id, auditActor(c)); err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "User unlocked successfully", nil)
+}
@@ -0,0 +1,443 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"example/internal/http/handler/requests"
+	"example/internal/http/handler/responses"
+	"example/internal/model"
+	"example/internal/service"
+	"example/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler defines the interface for organization handler operations
+type OrganizationHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Get(c *gin.Context)
+	ListMembers(c *gin.Context)
+	ListInvitations(c *gin.Context)
+	Invite(c *gin.Context)
+	CancelInvitation(c *gin.Context)
+	Accept(c *gin.Context)
+	Decline(c *gin.Context)
+	UpdateMember(c *gin.Context)
+	RemoveMember(c *gin.Context)
+}
+
+type organizationHandler struct {
+	service service.OrganizationService
+}
+
+func NewOrganizationHandler(service service.OrganizationService) OrganizationHandler {
+	return &organizationHandler{
+		service: service,
+	}
+}
+
+// Create godoc
+// @Summary Create an organization
+// @Description Create an organization owned by the logged in user
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param request body requests.OrganizationCreateRequest true "Organization"
+// @Success 201 {object} BaseResponse{data=responses.OrganizationResponse} "Organization created successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Router /organizations [post]
+func (h *organizationHandler) Create(c *gin.Context) {
+	var req requests.OrganizationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.service.Create(authenticatedUser.ID, req.Name)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusCreated, "Organization created successfully", responses.OrganizationResponseFromModel(org))
+}
+
+// List godoc
+// @Summary List my organizations
+// @Description List the organizations the logged in user is a member of, followed by those their email was invited to once it is verified
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} BaseResponse{data=[]responses.MembershipResponse} "Organizations retrieved successfully"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Router /organizations [get]
+func (h *organizationHandler) List(c *gin.Context) {
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	memberships, err := h.service.ListForUser(authenticatedUser.ID)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+	invitations, err := h.service.InvitationsForUser(authenticatedUser.ID)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	response := responses.MembershipResponsesFromModels(memberships)
+	for i := range invitations {
+		response = append(response, responses.MembershipResponseFromInvitation(&invitations[i], authenticatedUser.ID))
+	}
+	NewSuccessResponse(c, http.StatusOK, "Organizations retrieved successfully", response)
+}
+
+// Get godoc
+// @Summary Get an organization
+// @Description Get an organization the logged in user is a member of
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} BaseResponse{data=responses.OrganizationResponse} "Organization retrieved successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 404 {object} BaseResponse "Organization not found"
+// @Router /organizations/{id} [get]
+func (h *organizationHandler) Get(c *gin.Context) {
+	orgID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.service.Get(orgID, authenticatedUser.ID)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Organization retrieved successfully", responses.OrganizationResponseFromModel(org))
+}
+
+// ListMembers godoc
+// @Summary List members
+// @Description List the members of an organization the logged in user is a member of
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} BaseResponse{data=[]responses.MembershipResponse} "Members retrieved successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 404 {object} BaseResponse "Organization not found"
+// @Router /organizations/{id}/members [get]
+func (h *organizationHandler) ListMembers(c *gin.Context) {
+	orgID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	memberships, err := h.service.ListMembers(orgID, authenticatedUser.ID)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Members retrieved successfully", responses.MembershipResponsesFromModels(memberships))
+}
+
+// ListInvitations godoc
+// @Summary List invitations
+// @Description List the pending invitations of an organization the logged in user is a member of
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} BaseResponse{data=[]responses.InvitationResponse} "Invitations retrieved successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 404 {object} BaseResponse "Organization not found"
+// @Router /organizations/{id}/invitations [get]
+func (h *organizationHandler) ListInvitations(c *gin.Context) {
+	orgID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.service.ListInvitations(orgID, authenticatedUser.ID)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Invitations retrieved successfully", responses.InvitationResponsesFromModels(invitations))
+}
+
+// Invite godoc
+// @Summary Invite a member
+// @Description Invite an email to the organization, whether it has an account yet or not. Its owner joins once they verified the email and accepted the invitation. The answer doesn't tell whether the email has an account. Owners and admins can invite; only owners can invite owners.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param request body requests.MemberInviteRequest true "Invitation"
+// @Success 202 {object} BaseResponse{data=responses.InvitationResponse} "Invitation sent successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Not allowed to manage members"
+// @Failure 404 {object} BaseResponse "Organization not found"
+// @Failure 409 {object} BaseResponse "Email belongs to a member or was already invited"
+// @Failure 429 {object} BaseResponse "Too many invitations"
+// @Router /organizations/{id}/members [post]
+func (h *organizationHandler) Invite(c *gin.Context) {
+	orgID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.MemberInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.service.Invite(orgID, authenticatedUser.ID, req.Email, model.OrgRole(req.Role))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusAccepted, "Invitation sent successfully", responses.InvitationResponseFromModel(invitation))
+}
+
+// CancelInvitation godoc
+// @Summary Cancel an invitation
+// @Description Cancel the invitation of an email. Owners and admins can cancel invitations; only owners can cancel those inviting owners.
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param email query string true "Invited email"
+// @Success 200 {object} BaseResponse "Invitation cancelled successfully"
+// @Failure 400 {object} BaseResponse "Invalid query"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Not allowed to manage members"
+// @Failure 404 {object} BaseResponse "Organization or invitation not found"
+// @Router /organizations/{id}/invitations [delete]
+func (h *organizationHandler) CancelInvitation(c *gin.Context) {
+	orgID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.InvitationCancelRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid query", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelInvitation(orgID, authenticatedUser.ID, req.Email); err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Invitation cancelled successfully", nil)
+}
+
+// Accept godoc
+// @Summary Accept an invitation
+// @Description Join an organization the logged in user's email was invited to. The email must be verified. Log in again with its organization_id to act in it.
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} BaseResponse{data=responses.MembershipResponse} "Invitation accepted successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Email not verified"
+// @Failure 404 {object} BaseResponse "No pending invitation"
+// @Failure 409 {object} BaseResponse "Already a member"
+// @Router /organizations/{id}/accept [post]
+func (h *organizationHandler) Accept(c *gin.Context) {
+	orgID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	membership, err := h.service.Accept(orgID, authenticatedUser.ID)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Invitation accepted successfully", responses.MembershipResponseFromModel(membership))
+}
+
+// Decline godoc
+// @Summary Decline an invitation
+// @Description Decline the invitation the logged in user's email got to an organization
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} BaseResponse "Invitation declined successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 404 {object} BaseResponse "No pending invitation"
+// @Router /organizations/{id}/decline [post]
+func (h *organizationHandler) Decline(c *gin.Context) {
+	orgID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Decline(orgID, authenticatedUser.ID); err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Invitation declined successfully", nil)
+}
+
+// UpdateMember godoc
+// @Summary Change a member's role
+// @Description Change the organization role of a member. Owners and admins can change roles; only owners can make someone an owner or change an owner's role.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param user_id path int true "User ID"
+// @Param request body requests.MemberUpdateRequest true "New role"
+// @Success 200 {object} BaseResponse{data=responses.MembershipResponse} "Member updated successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Not allowed to manage members"
+// @Failure 404 {object} BaseResponse "Organization or member not found"
+// @Failure 409 {object} BaseResponse "Organization must keep an owner"
+// @Router /organizations/{id}/members/{user_id} [patch]
+func (h *organizationHandler) UpdateMember(c *gin.Context) {
+	orgID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := idParam(c, "user_id")
+	if !ok {
+		return
+	}
+
+	var req requests.MemberUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	membership, err := h.service.SetRole(orgID, authenticatedUser.ID, memberID, model.OrgRole(req.Role))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Member updated successfully", responses.MembershipResponseFromModel(membership))
+}
+
+// RemoveMember godoc
+// @Summary Remove a member
+// @Description Remove a member. Owners and admins can remove members, only owners can remove owners. Anyone can leave an organization by removing themselves, except its last owner.
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} BaseResponse "Member removed successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Not allowed to manage members"
+// @Failure 404 {object} BaseResponse "Organization or member not found"
+// @Failure 409 {object} BaseResponse "Organization must keep an owner"
+// @Router /organizations/{id}/members/{user_id} [delete]
+func (h *organizationHandler) RemoveMember(c *gin.Context) {
+	orgID, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := idParam(c, "user_id")
+	if !ok {
+		return
+	}
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveMember(orgID, authenticatedUser.ID, memberID); err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Member removed successfully", nil)
+}
+
+// idParam parses a numeric ID path parameter, responding with 400 when it
+// isn't one
+func idParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid ID", []interface{}{err.Error()})
+		return 0, false
+	}
+	return uint(id), true
+}
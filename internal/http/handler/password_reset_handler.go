@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"example/internal/http/handler/requests"
+	"example/internal/service"
+	"example/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordResetHandler defines the interface for password reset handler operations
+type PasswordResetHandler interface {
+	Forgot(c *gin.Context)
+	Reset(c *gin.Context)
+}
+
+type passwordResetHandler struct {
+	service service.PasswordResetService
+}
+
+func NewPasswordResetHandler(service service.PasswordResetService) PasswordResetHandler {
+	return &passwordResetHandler{
+		service: service,
+	}
+}
+
+// Forgot godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link to the user. The response is the same whether or not the email belongs to an account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.PasswordForgotRequest true "Account email"
+// @Success 202 {object} BaseResponse "Reset link sent if the account exists"
+// @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Failure 429 {object} BaseResponse "Too many requests"
+// @Router /auth/password/forgot [post]
+func (h *passwordResetHandler) Forgot(c *gin.Context) {
+	var req requests.PasswordForgotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	// The link is sent in the background; failures are logged by the
+	// service but not reported, as they would reveal that the account exists
+	h.service.RequestReset(req.Email)
+
+	NewSuccessResponse(c, http.StatusAccepted, "If an account exists for this email, a reset link has been sent", nil)
+}
+
+// Reset godoc
+// @Summary Reset password
+// @Description Set a new password using the token from a reset link. The token can only be used once, and every existing session of the user is logged out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body requests.PasswordResetRequest true "Reset token and new password"
+// @Success 200 {object} BaseResponse "Password reset successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload, invalid or expired token, or new password rejected by the policy"
+// @Failure 500 {object} BaseResponse "Internal server error"
+// @Router /auth/password/reset [post]
+func (h *passwordResetHandler) Reset(c *gin.Context) {
+	var req requests.PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	if err := h.service.ResetPassword(req.Token, req.NewPassword, auditActor(c)); err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Password reset successfully, please log in", nil)
+}
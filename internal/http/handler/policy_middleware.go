@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"example/internal/model"
+	"example/internal/policy"
+	"example/internal/repository"
+	"example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthorizeUser evaluates the policy for the user in the :id path parameter
+// and stores the resulting access for the handler. Users the policy hides are
+// answered exactly like users that don't exist. It must run after the auth
+// middleware.
+func AuthorizeUser(p policy.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			NewErrorResponse(c, http.StatusBadRequest, "Invalid ID", []interface{}{err.Error()})
+			c.Abort()
+			return
+		}
+
+		subject, ok := currentSubject(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		access := p.Evaluate(subject, policy.Resource{OwnerID: uint(id)})
+		if access == policy.AccessNone {
+			NewServiceErrorResponse(c, service.ErrUserNotFound)
+			c.Abort()
+			return
+		}
+
+		c.Set(accessKey, access)
+		c.Next()
+	}
+}
+
+// currentSubject builds the policy subject from the authenticated user and
+// the roles loaded by the authorizator
+func currentSubject(c *gin.Context) (policy.Subject, bool) {
+	user, ok := currentUser(c)
+	if !ok {
+		return policy.Subject{}, false
+	}
+	roles, ok := currentRoles(c)
+	if !ok {
+		return policy.Subject{}, false
+	}
+	return policy.Subject{UserID: user.ID, Roles: roles}, true
+}
+
+// currentAccess returns the access granted by the route's policy. Routes
+// without a policy get none, so a missing policy never exposes anything.
+func currentAccess(c *gin.Context) policy.Access {
+	access, _ := c.Get(accessKey)
+	a, _ := access.(policy.Access)
+	return a
+}
+
+// currentScope returns the users the caller may list and search: everyone for
+// staff allowed to read any user, the members of the organization the token
+// acts in, or else only the caller
+func currentScope(c *gin.Context) (repository.UserScope, bool) {
+	subject, ok := currentSubject(c)
+	if !ok {
+		return repository.UserScope{}, false
+	}
+
+	if model.HasPermission(subject.Roles, model.PermissionUsersRead) {
+		return repository.UserScope{All: true}, true
+	}
+	if membership, ok := c.Get(membershipKey); ok {
+		return repository.UserScope{OrganizationID: membership.(*model.Membership).OrganizationID}, true
+	}
+	return repository.UserScope{UserID: subject.UserID}, true
+}
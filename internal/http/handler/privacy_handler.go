@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"example/internal/http/handler/requests"
+	"example/internal/http/handler/responses"
+	"example/internal/service"
+	"example/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrivacyHandler defines the interface for data subject request handler
+// operations
+type PrivacyHandler interface {
+	ExportMe(c *gin.Context)
+	EraseMe(c *gin.Context)
+	Export(c *gin.Context)
+	Erase(c *gin.Context)
+}
+
+type privacyHandler struct {
+	service service.PrivacyService
+}
+
+func NewPrivacyHandler(service service.PrivacyService) PrivacyHandler {
+	return &privacyHandler{
+		service: service,
+	}
+}
+
+// ExportMe godoc
+// @Summary Download my data
+// @Description Download everything stored about the currently logged in user as a JSON file, including their avatar images and the audit log entries about them
+// @Tags users
+// @Produce json
+// @Success 200 {object} responses.UserDataArchiveResponse "Data archive"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Router /users/me/data [get]
+func (h *privacyHandler) ExportMe(c *gin.Context) {
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	h.export(c, authenticatedUser.ID)
+}
+
+// Export godoc
+// @Summary Download a user's data
+// @Description Download everything stored about a user as a JSON file, including their avatar images and the audit log entries about them, on the user's behalf
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} responses.UserDataArchiveResponse "Data archive"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Router /admin/users/{id}/data [get]
+func (h *privacyHandler) Export(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	h.export(c, id)
+}
+
+// export sends the user's data archive as a file download
+func (h *privacyHandler) export(c *gin.Context, userID uint) {
+	archive, err := h.service.Export(userID)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("user-%d-data-%s.json", userID, archive.ExportedAt.Format("20060102T150405Z"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.IndentedJSON(http.StatusOK, responses.UserDataArchiveResponseFromArchive(archive))
+}
+
+// EraseMe godoc
+// @Summary Erase my data
+// @Description Permanently anonymize the currently logged in user and log them out. The user is removed from their organizations and loses their roles. This can't be undone. Owners have to hand their organizations over to another owner first.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body requests.UserEraseRequest true "Current password"
+// @Success 200 {object} BaseResponse{data=responses.ErasureReceiptResponse} "User erased successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload or wrong current password"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Failure 409 {object} BaseResponse "User is the last owner of an organization"
+// @Router /users/me/erase [post]
+func (h *privacyHandler) EraseMe(c *gin.Context) {
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req requests.UserEraseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	receipt, err := h.service.EraseSelf(authenticatedUser.ID, req.CurrentPassword, auditActor(c))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "User erased successfully", responses.ErasureReceiptResponseFromModel(receipt))
+}
+
+// Erase godoc
+// @Summary Erase a user's data
+// @Description Permanently anonymize a user, deleted or not, on the user's behalf. The user is removed from their organizations and loses their roles. This can't be undone.
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} BaseResponse{data=responses.ErasureReceiptResponse} "User erased successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden"
+// @Failure 404 {object} BaseResponse "User not found or already erased"
+// @Failure 409 {object} BaseResponse "User is the last owner of an organization"
+// @Router /admin/users/{id}/erase [post]
+func (h *privacyHandler) Erase(c *gin.Context) {
+	id, ok := idParam(c, "id")
+	if !ok {
+		return
+	}
+
+	receipt, err := h.service.Erase(id, auditActor(c))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "User erased successfully", responses.ErasureReceiptResponseFromModel(receipt))
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitByIP answers with 429 once the client IP went over the limiter's
+// limit
+func RateLimitByIP(limiter service.RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := limiter.Allow(c.ClientIP()); err != nil {
+			NewServiceErrorResponse(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+
+	"example/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets through users with at least one of the roles. It must
+// run after the auth middleware, which loads the roles.
+func RequireRole(roles ...model.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		current, ok := currentRoles(c)
+		if !ok {
+			return
+		}
+		if !model.HasRole(current, roles...) {
+			NewErrorResponse(c, http.StatusForbidden, "Forbidden", []interface{}{"you don't have the role required for this action"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission only lets through users with a role granting the
+// permission. It must run after the auth middleware, which loads the roles.
+func RequirePermission(permission model.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		current, ok := currentRoles(c)
+		if !ok {
+			return
+		}
+		if !model.HasPermission(current, permission) {
+			NewErrorResponse(c, http.StatusForbidden, "Forbidden", []interface{}{"you don't have permission for this action"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// currentRoles returns the roles loaded by the authorizator, aborting with 401
+// when the request isn't authenticated
+func currentRoles(c *gin.Context) ([]model.Role, bool) {
+	roles, ok := c.Get(rolesKey)
+	if !ok {
+		NewErrorResponse(c, http.StatusUnauthorized, "Unauthorized", nil)
+		c.Abort()
+		return nil, false
+	}
+	current, ok := roles.([]model.Role)
+	if !ok {
+		NewErrorResponse(c, http.StatusUnauthorized, "Unauthorized", nil)
+		c.Abort()
+		return nil, false
+	}
+	return current, true
+}
@@ -0,0 +1,32 @@
+package requests
+
+import (
+	"example/internal/model"
+	"example/internal/repository"
+	"time"
+)
+
+// AuditEventListRequest represents the query parameters for listing audit
+// events
+type AuditEventListRequest struct {
+	ActorID  *uint      `form:"actor_id" validate:"omitempty,min=1" example:"1"`
+	TargetID *uint      `form:"target_id" validate:"omitempty,min=1" example:"2"`
+	Action   string     `form:"action" validate:"omitempty,max=64" example:"user.updated"`
+	From     *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-01-01T00:00:00Z"`
+	To       *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-02-01T00:00:00Z"`
+	Cursor   string     `form:"cursor"`
+	Limit    int        `form:"limit" validate:"omitempty,min=1,max=200" example:"50"`
+}
+
+// ToQuery converts AuditEventListRequest to repository.AuditEventQuery
+func (r *AuditEventListRequest) ToQuery() repository.AuditEventQuery {
+	return repository.AuditEventQuery{
+		ActorID:  r.ActorID,
+		TargetID: r.TargetID,
+		Action:   model.AuditAction(r.Action),
+		From:     r.From,
+		To:       r.To,
+		Cursor:   r.Cursor,
+		Limit:    r.Limit,
+	}
+}
@@ -0,0 +1,26 @@
+package requests
+
+// PasswordForgotRequest represents the request payload for asking for a
+// password reset link
+type PasswordForgotRequest struct {
+	Email string `json:"email" validate:"required,email" example:"john.doe@example.com"`
+}
+
+// PasswordResetRequest represents the request payload for setting a new
+// password with a reset token
+type PasswordResetRequest struct {
+	Token       string `json:"token" validate:"required" example:"q3J0Zk1hY2hpbmVSZWFkYWJsZVRva2VuMTIzNDU2Nzg"`
+	NewPassword string `json:"new_password" validate:"required" example:"NewPassword456"`
+}
+
+// EmailConfirmRequest represents the request payload for confirming an email
+// address with the token from a verification link
+type EmailConfirmRequest struct {
+	Token string `json:"token" validate:"required" example:"MTo0MTAyNDQ0ODAwOmpvaG4uZG9lQGV4YW1wbGUuY29t.c2lnbmF0dXJl"`
+}
+
+// EmailResendRequest represents the request payload for asking for a new
+// verification link
+type EmailResendRequest struct {
+	Email string `json:"email" validate:"required,email" example:"john.doe@example.com"`
+}
@@ -0,0 +1,7 @@
+package requests
+
+// CacheWarmupRequest represents the request payload for warming the user cache
+type CacheWarmupRequest struct {
+	// Recent limits the warmup to the N users who logged in most recently; 0 warms all users
+	Recent int `json:"recent" validate:"min=0" example:"1000"`
+}
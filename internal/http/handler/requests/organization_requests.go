@@ -0,0 +1,26 @@
+package requests
+
+// OrganizationCreateRequest represents the request payload for creating an
+// organization
+type OrganizationCreateRequest struct {
+	Name string `json:"name" validate:"required,max=255" example:"Acme Inc."`
+}
+
+// MemberInviteRequest represents the request payload for inviting a user to an
+// organization
+type MemberInviteRequest struct {
+	Email string `json:"email" validate:"required,email" example:"jane.doe@example.com"`
+	Role  string `json:"role" validate:"required,oneof=owner admin member" example:"member"`
+}
+
+// InvitationCancelRequest represents the query parameters for cancelling an
+// invitation
+type InvitationCancelRequest struct {
+	Email string `form:"email" validate:"required,email" example:"jane.doe@example.com"`
+}
+
+// MemberUpdateRequest represents the request payload for changing the role of
+// a member
+type MemberUpdateRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin member" example:"admin"`
+}
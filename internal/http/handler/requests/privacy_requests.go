@@ -0,0 +1,7 @@
+package requests
+
+// UserEraseRequest represents the request payload for erasing the logged in
+// user's personal data
+type UserEraseRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"Password123"`
+}
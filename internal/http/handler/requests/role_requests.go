@@ -0,0 +1,6 @@
+package requests
+
+// RoleGrantRequest represents the request payload for granting a role to a user
+type RoleGrantRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin support" example:"support"`
+}
@@ -0,0 +1,50 @@
+package requests
+
+import (
+	"example/internal/repository"
+	"example/internal/service"
+	"strings"
+	"time"
+)
+
+// UserExportRequest represents the query parameters for exporting users
+type UserExportRequest struct {
+	Format string `form:"format" validate:"omitempty,oneof=csv ndjson json" example:"csv"`
+	// Fields is a comma separated list of the fields to export, in order
+	Fields        string     `form:"fields" example:"id,email,created_at"`
+	Name          string     `form:"name" example:"john"`
+	Email         string     `form:"email" example:"example.com"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-01-01T00:00:00Z"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00" example:"2025-01-01T00:00:00Z"`
+}
+
+// ToOptions converts UserExportRequest to service.UserExportOptions, defaulting
+// to CSV
+func (r *UserExportRequest) ToOptions() service.UserExportOptions {
+	format := r.Format
+	if format == "" {
+		format = service.UserExportFormatCSV
+	}
+
+	return service.UserExportOptions{
+		Format: format,
+		Fields: SplitFields(r.Fields),
+		Filter: repository.UserListFilter{
+			Name:          r.Name,
+			Email:         r.Email,
+			CreatedAfter:  r.CreatedAfter,
+			CreatedBefore: r.CreatedBefore,
+		},
+	}
+}
+
+// SplitFields splits a comma separated field list, dropping empty entries
+func SplitFields(list string) []string {
+	var fields []string
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
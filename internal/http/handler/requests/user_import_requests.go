@@ -0,0 +1,185 @@
+package requests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"example/internal/service"
+	"example/pkg/validator"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// Formats accepted by user imports
+const (
+	UserImportFormatCSV    = "csv"
+	UserImportFormatNDJSON = "ndjson"
+)
+
+// maxImportLineBytes bounds a single NDJSON line. Longer lines fail their row
+// without being read into memory.
+const maxImportLineBytes = 1 << 20
+
+// UserImportRequest represents the query parameters of a user import. The
+// file itself is the request body.
+type UserImportRequest struct {
+	// Format defaults to the one of the Content-Type header
+	Format string `form:"format" validate:"omitempty,oneof=csv ndjson" example:"csv"`
+	DryRun bool   `form:"dry_run"`
+}
+
+// UserImportFormatFromContentType returns the import format of a Content-Type
+// header, empty if it isn't one
+func UserImportFormatFromContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return UserImportFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return UserImportFormatNDJSON
+	}
+	return ""
+}
+
+// NewUserImportSource decodes the users of an import file. Every row is
+// validated with the rules of UserCreateRequest, so an imported user is held
+// to the same standard as one created through the API. CSV files need a
+// header naming the name, email and password columns; other columns are
+// ignored.
+func NewUserImportSource(r io.Reader, format string) (service.UserImportSource, error) {
+	switch format {
+	case UserImportFormatCSV:
+		return newCSVUserSource(r)
+	case UserImportFormatNDJSON:
+		return &ndjsonUserSource{reader: bufio.NewReaderSize(r, 64<<10)}, nil
+	}
+	return nil, fmt.Errorf("unsupported import format %q", format)
+}
+
+// importRow validates a decoded request and converts it into an import row
+func importRow(line int, req UserCreateRequest) *service.UserImportRow {
+	return &service.UserImportRow{
+		Line:   line,
+		User:   req.ToModel(),
+		Fields: validator.ValidateStruct(req),
+	}
+}
+
+type csvUserSource struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVUserSource(r io.Reader) (*csvUserSource, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "email", "password"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header has no %s column", required)
+		}
+	}
+
+	return &csvUserSource{reader: reader, columns: columns}, nil
+}
+
+func (s *csvUserSource) Next() (*service.UserImportRow, error) {
+	record, err := s.reader.Read()
+	// Records with a missing or extra column are still read; a short record
+	// then fails validation on the missing fields
+	if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+		// A malformed record only fails its own row
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &service.UserImportRow{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+		}
+		return nil, err
+	}
+
+	line, _ := s.reader.FieldPos(0)
+	return importRow(line, UserCreateRequest{
+		Name:     s.field(record, "name"),
+		Email:    s.field(record, "email"),
+		Password: s.field(record, "password"),
+	}), nil
+}
+
+// field returns the named column of the record, empty if the record is short
+func (s *csvUserSource) field(record []string, name string) string {
+	i := s.columns[name]
+	if i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+type ndjsonUserSource struct {
+	reader *bufio.Reader
+	line   int
+}
+
+func (s *ndjsonUserSource) Next() (*service.UserImportRow, error) {
+	for {
+		raw, tooLong, err := s.readLine()
+		if err != nil {
+			return nil, err
+		}
+		s.line++
+		if tooLong {
+			return &service.UserImportRow{Line: s.line, Err: fmt.Errorf("line is longer than %d bytes", maxImportLineBytes)}, nil
+		}
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+
+		var req UserCreateRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			return &service.UserImportRow{Line: s.line, Err: fmt.Errorf("invalid JSON: %w", err)}, nil
+		}
+		return importRow(s.line, req), nil
+	}
+}
+
+// readLine returns the next line, or io.EOF after the last one. A line longer
+// than maxImportLineBytes is skipped up to its end and reported as too long.
+func (s *ndjsonUserSource) readLine() ([]byte, bool, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := s.reader.ReadSlice('\n')
+		if !tooLong {
+			// The newline doesn't count towards the limit
+			if len(line)+len(chunk) > maxImportLineBytes+1 {
+				tooLong, line = true, nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && (len(line) > 0 || tooLong):
+			// The last line has no newline; io.EOF follows on the next call
+			return line, tooLong, nil
+		}
+		return line, tooLong, err
+	}
+}
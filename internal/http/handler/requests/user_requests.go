@@ -1,12 +1,18 @@
 package requests
 
-import "example/internal/model"
+import (
+	"example/internal/model"
+	"example/internal/repository"
+	"example/internal/service"
+	"strings"
+	"time"
+)
 
 // UserCreateRequest represents the request payload for creating a user
 type UserCreateRequest struct {
 	Name     string `json:"name" validate:"required" example:"John Doe"`
 	Email    string `json:"email" validate:"required,email" example:"john.doe@example.com"`
-	Password string `json:"password" validate:"required,min=6" example:"password123"`
+	Password string `json:"password" validate:"required" example:"Password123"`
 }
 
 // ToModel converts UserCreateRequest to model.User
@@ -18,8 +24,77 @@ func (r *UserCreateRequest) ToModel() *model.User {
 	}
 }
 
+// UserUpdateRequest represents the request payload for partially updating a
+// user. Omitted fields are left unchanged; provided ones follow the same rules
+// as UserCreateRequest. The expected version may be sent here or in If-Match.
+type UserUpdateRequest struct {
+	Name    *string `json:"name,omitempty" validate:"omitnil,required" example:"John Doe"`
+	Email   *string `json:"email,omitempty" validate:"omitnil,required,email" example:"john.doe@example.com"`
+	Version *uint   `json:"version,omitempty" example:"1"`
+}
+
+// ToUpdate converts UserUpdateRequest to service.UserUpdate
+func (r *UserUpdateRequest) ToUpdate() service.UserUpdate {
+	return service.UserUpdate{
+		Name:  r.Name,
+		Email: r.Email,
+	}
+}
+
+// PasswordChangeRequest represents the request payload for changing the
+// logged in user's password
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"Password123"`
+	NewPassword     string `json:"new_password" validate:"required" example:"NewPassword456"`
+}
+
+// UserListRequest represents the query parameters for listing users
+type UserListRequest struct {
+	Limit         int        `form:"limit" validate:"omitempty,min=1,max=100" example:"20"`
+	Cursor        string     `form:"cursor"`
+	Name          string     `form:"name" example:"john"`
+	Email         string     `form:"email" example:"example.com"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-01-01T00:00:00Z"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00" example:"2025-01-01T00:00:00Z"`
+	// Sort is a column name, prefixed with - for descending order
+	Sort         string `form:"sort" validate:"omitempty,oneof=created_at -created_at name -name email -email id -id" example:"-created_at"`
+	IncludeTotal bool   `form:"include_total"`
+}
+
+// ToQuery converts UserListRequest to repository.UserListQuery
+func (r *UserListRequest) ToQuery() repository.UserListQuery {
+	return repository.UserListQuery{
+		Filter: repository.UserListFilter{
+			Name:          r.Name,
+			Email:         r.Email,
+			CreatedAfter:  r.CreatedAfter,
+			CreatedBefore: r.CreatedBefore,
+		},
+		SortBy:       strings.TrimPrefix(r.Sort, "-"),
+		SortDesc:     strings.HasPrefix(r.Sort, "-"),
+		Cursor:       r.Cursor,
+		Limit:        r.Limit,
+		IncludeTotal: r.IncludeTotal,
+	}
+}
+
+// UserSearchRequest represents the query parameters for searching users
+type UserSearchRequest struct {
+	Query string `form:"q" validate:"required,min=2,max=100" example:"john"`
+	Limit int    `form:"limit" validate:"omitempty,min=1,max=50" example:"20"`
+}
+
 // LoginRequest represents the request payload for user login
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email" example:"john.doe@example.com"`
 	Password string `json:"password" validate:"required" example:"password123"`
+	// OrganizationID picks the organization the token acts in, defaulting to
+	// the first one the user joined
+	OrganizationID *uint `json:"organization_id,omitempty" example:"1"`
+}
+
+// UserStatusRequest represents the request payload for suspending, disabling
+// or reactivating a user
+type UserStatusRequest struct {
+	Reason string `json:"reason" validate:"max=500" example:"Spamming other users"`
 }
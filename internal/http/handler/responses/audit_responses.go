@@ -0,0 +1,68 @@
+package responses
+
+import (
+	"example/internal/model"
+	"example/internal/repository"
+	"time"
+)
+
+// AuditEventResponse represents an entry of the audit log
+type AuditEventResponse struct {
+	ID         uint64 `json:"id" example:"42"`
+	OccurredAt string `json:"occurred_at" example:"2024-01-01T10:00:00Z"`
+	Action     string `json:"action" example:"user.updated"`
+	// ActorID is who made the change, omitted for anonymous requests and
+	// the command line
+	ActorID   *uint  `json:"actor_id,omitempty" example:"1"`
+	TargetID  *uint  `json:"target_id,omitempty" example:"2"`
+	IP        string `json:"ip" example:"203.0.113.7"`
+	UserAgent string `json:"user_agent" example:"Mozilla/5.0"`
+	// Changes maps each changed field to its value before and after
+	Changes  model.AuditChanges  `json:"changes,omitempty" swaggertype:"object"`
+	Metadata model.AuditMetadata `json:"metadata,omitempty" swaggertype:"object"`
+}
+
+// AuditEventResponseFromModel creates AuditEventResponse from
+// model.AuditEvent
+func AuditEventResponseFromModel(event *model.AuditEvent) *AuditEventResponse {
+	return &AuditEventResponse{
+		ID:         event.ID,
+		OccurredAt: event.OccurredAt.UTC().Format(time.RFC3339),
+		Action:     string(event.Action),
+		ActorID:    event.ActorID,
+		TargetID:   event.TargetID,
+		IP:         event.IP,
+		UserAgent:  event.UserAgent,
+		Changes:    event.Changes,
+		Metadata:   event.Metadata,
+	}
+}
+
+// AuditEventResponsesFromModels creates an AuditEventResponse for each
+// model.AuditEvent
+func AuditEventResponsesFromModels(events []model.AuditEvent) []*AuditEventResponse {
+	result := make([]*AuditEventResponse, len(events))
+	for i := range events {
+		result[i] = AuditEventResponseFromModel(&events[i])
+	}
+	return result
+}
+
+// AuditEventPageResponse represents a page of audit events
+type AuditEventPageResponse struct {
+	Events     []*AuditEventResponse `json:"events"`
+	NextCursor string                `json:"next_cursor,omitempty" example:"eyJpZCI6NDJ9"`
+	HasMore    bool                  `json:"has_more" example:"true"`
+	Limit      int                   `json:"limit" example:"50"`
+}
+
+// AuditEventPageResponseFromPage creates AuditEventPageResponse from
+// repository.AuditEventPage
+func AuditEventPageResponseFromPage(page *repository.AuditEventPage) *AuditEventPageResponse {
+	return &AuditEventPageResponse{
+		Events:     AuditEventResponsesFromModels(page.Events),
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+		Limit:      page.Limit,
+	}
+}
@@ -0,0 +1,43 @@
+package responses
+
+import (
+	"encoding/json"
+	"example/internal/service"
+	"example/pkg/cache"
+	"time"
+)
+
+// CacheWarmupResponse represents the result of a cache warmup
+type CacheWarmupResponse struct {
+	Warmed int `json:"warmed" example:"1000"`
+}
+
+// CacheTTLResponse represents the remaining lifetime of a cache key
+type CacheTTLResponse struct {
+	Key        string `json:"key" example:"user:1"`
+	TTLSeconds int64  `json:"ttl_seconds" example:"3540"`
+	Persistent bool   `json:"persistent" example:"false"`
+}
+
+// CacheEntryResponse represents a cached value and its remaining lifetime
+type CacheEntryResponse struct {
+	CacheTTLResponse
+	Value json.RawMessage `json:"value" swaggertype:"object"`
+}
+
+// CacheTTLResponseFromDuration creates CacheTTLResponse from a key TTL
+func CacheTTLResponseFromDuration(key string, ttl time.Duration) *CacheTTLResponse {
+	if ttl == cache.NoExpiration {
+		return &CacheTTLResponse{Key: key, TTLSeconds: -1, Persistent: true}
+	}
+
+	return &CacheTTLResponse{Key: key, TTLSeconds: int64(ttl.Seconds())}
+}
+
+// CacheEntryResponseFromEntry creates CacheEntryResponse from service.CacheEntry
+func CacheEntryResponseFromEntry(entry *service.CacheEntry) *CacheEntryResponse {
+	return &CacheEntryResponse{
+		CacheTTLResponse: *CacheTTLResponseFromDuration(entry.Key, entry.TTL),
+		Value:            entry.Value,
+	}
+}
@@ -0,0 +1,8 @@
+package responses
+
+// HealthResponse represents the health of the service and its dependencies
+type HealthResponse struct {
+	Status   string `json:"status" example:"ok"`
+	Database string `json:"database" example:"up"`
+	Cache    string `json:"cache" example:"closed"`
+}
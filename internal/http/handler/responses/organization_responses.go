@@ -0,0 +1,142 @@
+package responses
+
+import "example/internal/model"
+
+// Membership statuses
+const (
+	MembershipStatusActive  = "active"
+	MembershipStatusInvited = "invited"
+)
+
+// OrganizationResponse represents the response payload for organization data
+type OrganizationResponse struct {
+	ID        uint   `json:"id" example:"1"`
+	Name      string `json:"name" example:"Acme Inc."`
+	CreatedAt string `json:"created_at" example:"2024-01-01 10:00:00"`
+}
+
+// OrganizationResponseFromModel creates OrganizationResponse from
+// model.Organization
+func OrganizationResponseFromModel(org *model.Organization) *OrganizationResponse {
+	return &OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		CreatedAt: org.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// MemberUserResponse represents a member as the other members of the
+// organization see them
+type MemberUserResponse struct {
+	ID         uint              `json:"id" example:"2"`
+	Name       string            `json:"name" example:"Jane Doe"`
+	Email      string            `json:"email" example:"jane.doe@example.com"`
+	AvatarURLs map[string]string `json:"avatar_urls,omitempty" example:"64:https://cdn.example.com/avatars/2/3f2a9c/64.png"`
+}
+
+// MembershipResponse represents a membership or an invitation of the user.
+// The organization is included when listing a user's organizations, the user
+// when listing an organization's members.
+type MembershipResponse struct {
+	OrganizationID uint    `json:"organization_id" example:"1"`
+	UserID         uint    `json:"user_id" example:"2"`
+	Role           string  `json:"role" example:"member"`
+	Status         string  `json:"status" example:"active" enums:"active,invited"`
+	InvitedBy      *uint   `json:"invited_by,omitempty" example:"1"`
+	CreatedAt      string  `json:"created_at" example:"2024-01-01 10:00:00"`
+	AcceptedAt     *string `json:"accepted_at,omitempty" example:"2024-01-02 10:00:00"`
+
+	Organization *OrganizationResponse `json:"organization,omitempty"`
+	User         *MemberUserResponse   `json:"user,omitempty"`
+}
+
+// MembershipResponseFromModel creates MembershipResponse from
+// model.Membership
+func MembershipResponseFromModel(membership *model.Membership) *MembershipResponse {
+	response := &MembershipResponse{
+		OrganizationID: membership.OrganizationID,
+		UserID:         membership.UserID,
+		Role:           string(membership.Role),
+		Status:         MembershipStatusInvited,
+		InvitedBy:      membership.InvitedBy,
+		CreatedAt:      membership.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if membership.Active() {
+		acceptedAt := membership.AcceptedAt.Format("2006-01-02 15:04:05")
+		response.Status = MembershipStatusActive
+		response.AcceptedAt = &acceptedAt
+	}
+	if membership.Organization != nil {
+		response.Organization = OrganizationResponseFromModel(membership.Organization)
+	}
+	if user := membership.User; user != nil {
+		response.User = &MemberUserResponse{
+			ID:         user.ID,
+			Name:       user.Name,
+			Email:      user.Email,
+			AvatarURLs: user.AvatarURLs(),
+		}
+	}
+	return response
+}
+
+// MembershipResponsesFromModels creates a MembershipResponse for each
+// model.Membership
+func MembershipResponsesFromModels(memberships []model.Membership) []*MembershipResponse {
+	result := make([]*MembershipResponse, len(memberships))
+	for i := range memberships {
+		result[i] = MembershipResponseFromModel(&memberships[i])
+	}
+	return result
+}
+
+// MembershipResponseFromInvitation creates an invited MembershipResponse from
+// an invitation sent to the user
+func MembershipResponseFromInvitation(invitation *model.Invitation, userID uint) *MembershipResponse {
+	response := &MembershipResponse{
+		OrganizationID: invitation.OrganizationID,
+		UserID:         userID,
+		Role:           string(invitation.Role),
+		Status:         MembershipStatusInvited,
+		InvitedBy:      invitation.InvitedBy,
+		CreatedAt:      invitation.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if invitation.Organization != nil {
+		response.Organization = OrganizationResponseFromModel(invitation.Organization)
+	}
+	return response
+}
+
+// InvitationResponse represents an invitation to join an organization. It
+// names the email invited, never the account owning it.
+type InvitationResponse struct {
+	OrganizationID uint   `json:"organization_id" example:"1"`
+	Email          string `json:"email" example:"jane.doe@example.com"`
+	Role           string `json:"role" example:"member"`
+	Status         string `json:"status" example:"invited" enums:"invited"`
+	InvitedBy      *uint  `json:"invited_by,omitempty" example:"1"`
+	CreatedAt      string `json:"created_at" example:"2024-01-01 10:00:00"`
+}
+
+// InvitationResponseFromModel creates InvitationResponse from
+// model.Invitation
+func InvitationResponseFromModel(invitation *model.Invitation) *InvitationResponse {
+	return &InvitationResponse{
+		OrganizationID: invitation.OrganizationID,
+		Email:          invitation.Email,
+		Role:           string(invitation.Role),
+		Status:         MembershipStatusInvited,
+		InvitedBy:      invitation.InvitedBy,
+		CreatedAt:      invitation.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// InvitationResponsesFromModels creates an InvitationResponse for each
+// model.Invitation
+func InvitationResponsesFromModels(invitations []model.Invitation) []*InvitationResponse {
+	result := make([]*InvitationResponse, len(invitations))
+	for i := range invitations {
+		result[i] = InvitationResponseFromModel(&invitations[i])
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package responses
+
+import (
+	"example/internal/model"
+	"example/internal/service"
+	"time"
+)
+
+// UserDataArchiveResponse represents everything stored about a user, as
+// downloaded on a data export request. Password hashes and reset token hashes
+// are secrets rather than personal data and are never included.
+type UserDataArchiveResponse struct {
+	ExportedAt        string                   `json:"exported_at" example:"2024-01-01T10:00:00Z"`
+	User              *UserResponse            `json:"user"`
+	EmailVerifiedAt   *string                  `json:"email_verified_at,omitempty" example:"2024-01-01 10:05:00"`
+	PasswordChangedAt *string                  `json:"password_changed_at,omitempty" example:"2024-02-01 10:00:00"`
+	StatusReason      *string                  `json:"status_reason,omitempty" example:"Spamming other users"`
+	StatusChangedAt   *string                  `json:"status_changed_at,omitempty" example:"2024-03-01 10:00:00"`
+	LastLoginAt       *string                  `json:"last_login_at,omitempty" example:"2024-03-02 09:00:00"`
+	Roles             []string                 `json:"roles" example:"user"`
+	Memberships       []*MembershipResponse    `json:"memberships"`
+	Invitations       []*MembershipResponse    `json:"invitations"`
+	PasswordResets    []*PasswordResetResponse `json:"password_resets"`
+	// Avatar holds the stored avatar thumbnails, empty without an avatar
+	Avatar      []*AvatarFileResponse `json:"avatar"`
+	AuditEvents []*AuditEventResponse `json:"audit_events"`
+}
+
+// AvatarFileResponse represents a stored avatar thumbnail
+type AvatarFileResponse struct {
+	Size        int    `json:"size" example:"64"`
+	ContentType string `json:"content_type" example:"image/png"`
+	// Data is the image, base64-encoded
+	Data []byte `json:"data" swaggertype:"string" format:"base64" example:"iVBORw0KGgo="`
+}
+
+// PasswordResetResponse represents a password reset the user requested
+type PasswordResetResponse struct {
+	RequestedAt string  `json:"requested_at" example:"2024-01-01 10:00:00"`
+	ExpiresAt   string  `json:"expires_at" example:"2024-01-01 11:00:00"`
+	UsedAt      *string `json:"used_at,omitempty" example:"2024-01-01 10:10:00"`
+}
+
+// UserDataArchiveResponseFromArchive creates UserDataArchiveResponse from
+// service.UserDataArchive
+func UserDataArchiveResponseFromArchive(archive *service.UserDataArchive) *UserDataArchiveResponse {
+	response := &UserDataArchiveResponse{
+		ExportedAt:        archive.ExportedAt.Format(time.RFC3339),
+		User:              UserResponseFromModel(archive.User),
+		EmailVerifiedAt:   formatOptionalTime(archive.User.EmailVerifiedAt),
+		PasswordChangedAt: formatOptionalTime(archive.User.PasswordChangedAt),
+		StatusReason:      archive.User.StatusReason,
+		StatusChangedAt:   formatOptionalTime(archive.User.StatusChangedAt),
+		LastLoginAt:       formatOptionalTime(archive.User.LastLoginAt),
+		Roles:             UserRolesResponseFromModel(archive.User.ID, archive.Roles).Roles,
+		Memberships:       MembershipResponsesFromModels(archive.Memberships),
+		Invitations:       make([]*MembershipResponse, len(archive.Invitations)),
+		PasswordResets:    make([]*PasswordResetResponse, len(archive.PasswordResets)),
+		Avatar:            make([]*AvatarFileResponse, len(archive.Avatar)),
+		AuditEvents:       AuditEventResponsesFromModels(archive.AuditEvents),
+	}
+	for i, file := range archive.Avatar {
+		response.Avatar[i] = &AvatarFileResponse{Size: file.Size, ContentType: "image/png", Data: file.Data}
+	}
+	for i := range archive.Invitations {
+		response.Invitations[i] = MembershipResponseFromInvitation(&archive.Invitations[i], archive.User.ID)
+	}
+	for i, token := range archive.PasswordResets {
+		response.PasswordResets[i] = &PasswordResetResponse{
+			RequestedAt: token.CreatedAt.Format("2006-01-02 15:04:05"),
+			ExpiresAt:   token.ExpiresAt.Format("2006-01-02 15:04:05"),
+			UsedAt:      formatOptionalTime(token.UsedAt),
+		}
+	}
+	return response
+}
+
+// ErasureReceiptResponse represents the receipt of an erasure. Hash chains
+// the receipt to every earlier one, so it can later be shown not to have been
+// altered.
+type ErasureReceiptResponse struct {
+	ID          uint   `json:"id" example:"12"`
+	UserID      uint   `json:"user_id" example:"1"`
+	EmailHash   string `json:"email_hash" example:"836f82db99121b3481011f16b49dfa5fbc714a0d1b1b9f784a1ebbbf5b39577f"`
+	RequestedBy *uint  `json:"requested_by,omitempty" example:"1"`
+	ErasedAt    string `json:"erased_at" example:"2024-01-01T10:00:00.123456Z"`
+	PrevHash    string `json:"prev_hash" example:"5d41402abc4b2a76b9719d911017c592a8e8f2f6f1e4b0c3d2a1f0e9d8c7b6a5"`
+	Hash        string `json:"hash" example:"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"`
+}
+
+// ErasureReceiptResponseFromModel creates ErasureReceiptResponse from
+// model.ErasureReceipt
+func ErasureReceiptResponseFromModel(receipt *model.ErasureReceipt) *ErasureReceiptResponse {
+	return &ErasureReceiptResponse{
+		ID:          receipt.ID,
+		UserID:      receipt.UserID,
+		EmailHash:   receipt.EmailHash,
+		RequestedBy: receipt.RequestedBy,
+		ErasedAt:    receipt.ErasedAt.UTC().Format(time.RFC3339Nano),
+		PrevHash:    receipt.PrevHash,
+		Hash:        receipt.Hash,
+	}
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
+}
@@ -0,0 +1,22 @@
+package responses
+
+import "example/internal/model"
+
+// UserRolesResponse represents the roles of a user, including the implicit
+// user role
+type UserRolesResponse struct {
+	UserID uint     `json:"user_id" example:"1"`
+	Roles  []string `json:"roles" example:"user,support"`
+}
+
+// UserRolesResponseFromModel creates UserRolesResponse from a user's roles
+func UserRolesResponseFromModel(userID uint, roles []model.Role) *UserRolesResponse {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return &UserRolesResponse{
+		UserID: userID,
+		Roles:  names,
+	}
+}
@@ -0,0 +1,59 @@
+package responses
+
+import (
+	"example/internal/service"
+	"example/pkg/validator"
+)
+
+// UserImportRowResponse represents the outcome of importing one row
+type UserImportRowResponse struct {
+	Line   int                         `json:"line" example:"2"`
+	Email  string                      `json:"email,omitempty" example:"john.doe@example.com"`
+	Status string                      `json:"status" example:"created" enums:"created,valid,failed"`
+	UserID uint                        `json:"user_id,omitempty" example:"1"`
+	Error  string                      `json:"error,omitempty" example:"email is already in use"`
+	Fields []validator.ValidationError `json:"fields,omitempty"`
+}
+
+// UserImportResponse represents the report of a user import
+type UserImportResponse struct {
+	DryRun  bool `json:"dry_run" example:"false"`
+	Total   int  `json:"total" example:"1000"`
+	Created int  `json:"created" example:"998"`
+	// Valid counts the rows that would have been created on a dry run
+	Valid  int `json:"valid" example:"0"`
+	Failed int `json:"failed" example:"2"`
+	// Truncated is set when the file had more rows than an import accepts
+	Truncated bool `json:"truncated" example:"false"`
+	// Error is set when the import stopped part way after creating users;
+	// rows missing from the report weren't imported
+	Error string                   `json:"error,omitempty" example:"import stopped early, rows missing from the report were not imported"`
+	Rows  []*UserImportRowResponse `json:"rows"`
+}
+
+// UserImportResponseFromReport creates UserImportResponse from
+// service.UserImportReport
+func UserImportResponseFromReport(report *service.UserImportReport) *UserImportResponse {
+	rows := make([]*UserImportRowResponse, len(report.Rows))
+	for i, row := range report.Rows {
+		rows[i] = &UserImportRowResponse{
+			Line:   row.Line,
+			Email:  row.Email,
+			Status: row.Status,
+			UserID: row.UserID,
+			Error:  row.Error,
+			Fields: row.Fields,
+		}
+	}
+
+	return &UserImportResponse{
+		DryRun:    report.DryRun,
+		Total:     report.Total,
+		Created:   report.Created,
+		Valid:     report.Valid,
+		Failed:    report.Failed,
+		Truncated: report.Truncated,
+		Error:     report.Error,
+		Rows:      rows,
+	}
+}
@@ -1,6 +1,9 @@
 package responses
 
-import "example/internal/model"
+import (
+	"example/internal/model"
+	"example/internal/repository"
+)
 
 // UserResponse represents the response payload for user data
 type UserResponse struct {
@@ -9,15 +12,103 @@ type UserResponse struct {
 	Email     string `json:"email" example:"john.doe@example.com"`
 	CreatedAt string `json:"created_at" example:"2024-01-01 10:00:00"`
 	UpdatedAt string `json:"updated_at" example:"2024-01-01 10:00:00"`
+	Version   uint   `json:"version" example:"1"`
+	// EmailVerified is false until the user opened the verification link
+	// sent to their email
+	EmailVerified bool `json:"email_verified" example:"true"`
+	// AvatarURLs maps each thumbnail size in pixels to its URL, omitted when
+	// the user has no avatar
+	AvatarURLs map[string]string `json:"avatar_urls,omitempty" example:"64:https://cdn.example.com/avatars/1/3f2a9c/64.png"`
+	// Status is whether the user may log in
+	Status string `json:"status" example:"active" enums:"active,suspended,disabled"`
 }
 
 // FromModel creates UserResponse from model.User
 func UserResponseFromModel(user *model.User) *UserResponse {
 	return &UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02 15:04:05"),
+		ID:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		CreatedAt:     user.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     user.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Version:       user.Version,
+		EmailVerified: user.EmailVerified(),
+		AvatarURLs:    user.AvatarURLs(),
+		Status:        string(userStatus(user)),
 	}
 }
+
+// UserStatusResponse represents a user's status as admins see it, with why
+// and when it was last changed
+type UserStatusResponse struct {
+	User      *UserResponse `json:"user"`
+	Reason    *string       `json:"reason,omitempty" example:"Spamming other users"`
+	ChangedAt *string       `json:"changed_at,omitempty" example:"2024-01-01 10:00:00"`
+}
+
+// UserStatusResponseFromModel creates UserStatusResponse from model.User
+func UserStatusResponseFromModel(user *model.User) *UserStatusResponse {
+	return &UserStatusResponse{
+		User:      UserResponseFromModel(user),
+		Reason:    user.StatusReason,
+		ChangedAt: formatOptionalTime(user.StatusChangedAt),
+	}
+}
+
+// userStatus is the user's status, active for users cached before it existed
+func userStatus(user *model.User) model.UserStatus {
+	if user.Status == "" {
+		return model.UserStatusActive
+	}
+	return user.Status
+}
+
+// PublicUserResponse represents the profile of a user as other users see it
+type PublicUserResponse struct {
+	ID   uint   `json:"id" example:"1"`
+	Name string `json:"name" example:"John Doe"`
+	// AvatarURLs maps each thumbnail size in pixels to its URL, omitted when
+	// the user has no avatar
+	AvatarURLs map[string]string `json:"avatar_urls,omitempty" example:"64:https://cdn.example.com/avatars/1/3f2a9c/64.png"`
+}
+
+// PublicUserResponseFromModel creates PublicUserResponse from model.User
+func PublicUserResponseFromModel(user *model.User) *PublicUserResponse {
+	return &PublicUserResponse{
+		ID:         user.ID,
+		Name:       user.Name,
+		AvatarURLs: user.AvatarURLs(),
+	}
+}
+
+// UserResponsesFromModels creates a UserResponse for each model.User
+func UserResponsesFromModels(users []model.User) []*UserResponse {
+	result := make([]*UserResponse, len(users))
+	for i := range users {
+		result[i] = UserResponseFromModel(&users[i])
+	}
+	return result
+}
+
+// UserSearchResultResponse represents a user matching a search
+type UserSearchResultResponse struct {
+	User           *UserResponse `json:"user"`
+	Rank           float64       `json:"rank" example:"0.87"`
+	NameHighlight  string        `json:"name_highlight" example:"<mark>John</mark> Doe"`
+	EmailHighlight string        `json:"email_highlight" example:"<mark>john</mark>.doe@example.com"`
+}
+
+// UserSearchResultResponsesFromResults creates UserSearchResultResponse from
+// repository.UserSearchResult
+func UserSearchResultResponsesFromResults(results []repository.UserSearchResult) []*UserSearchResultResponse {
+	response := make([]*UserSearchResultResponse, len(results))
+	for i := range results {
+		response[i] = &UserSearchResultResponse{
+			User:           UserResponseFromModel(&results[i].User),
+			Rank:           results[i].Rank,
+			NameHighlight:  results[i].NameHighlight,
+			EmailHighlight: results[i].EmailHighlight,
+		}
+	}
+	return response
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"example/internal/http/handler/requests"
+	"example/internal/http/handler/responses"
+	"example/internal/model"
+	"example/internal/service"
+	"example/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler defines the interface for role administration handler operations
+type RoleHandler interface {
+	List(c *gin.Context)
+	Grant(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+type roleHandler struct {
+	service service.RoleService
+}
+
+func NewRoleHandler(service service.RoleService) RoleHandler {
+	return &roleHandler{
+		service: service,
+	}
+}
+
+// List godoc
+// @Summary Get user roles
+// @Description Get the roles of a user. Every user has the user role.
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} BaseResponse{data=responses.UserRolesResponse} "Roles retrieved successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden"
+// @Router /admin/users/{id}/roles [get]
+func (h *roleHandler) List(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid ID", []interface{}{err.Error()})
+		return
+	}
+
+	roles, err := h.service.Roles(uint(id))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Roles retrieved successfully", responses.UserRolesResponseFromModel(uint(id), roles))
+}
+
+// Grant godoc
+// @Summary Grant a role
+// @Description Grant the admin or support role to a user. Granting a role the user already has succeeds without changes.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body requests.RoleGrantRequest true "Role to grant"
+// @Success 200 {object} BaseResponse{data=responses.UserRolesResponse} "Role granted successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Router /admin/users/{id}/roles [post]
+func (h *roleHandler) Grant(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid ID", []interface{}{err.Error()})
+		return
+	}
+
+	var req requests.RoleGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	roles, err := h.service.Grant(uint(id), model.Role(req.Role), auditActor(c))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Role granted successfully", responses.UserRolesResponseFromModel(uint(id), roles))
+}
+
+// Revoke godoc
+// @Summary Revoke a role
+// @Description Revoke the admin or support role from a user. Admins cannot revoke their own admin role.
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Param role path string true "Role" Enums(admin, support)
+// @Success 200 {object} BaseResponse{data=responses.UserRolesResponse} "Role revoked successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID or role"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden"
+// @Failure 404 {object} BaseResponse "User not found or role not granted"
+// @Failure 409 {object} BaseResponse "Cannot revoke own admin role"
+// @Router /admin/users/{id}/roles/{role} [delete]
+func (h *roleHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid ID", []interface{}{err.Error()})
+		return
+	}
+
+	// The actor must carry the user, it keeps admins from revoking their own
+	// admin role
+	if _, ok := currentUser(c); !ok {
+		return
+	}
+
+	roles, err := h.service.Revoke(uint(id), model.Role(c.Param("role")), auditActor(c))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Role revoked successfully", responses.UserRolesResponseFromModel(uint(id), roles))
+}
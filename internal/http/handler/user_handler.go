@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"example/internal/http/handler/requests"
 	"example/internal/http/handler/responses"
 	"example/internal/model"
+	"example/internal/policy"
 	"example/internal/service"
+	"example/pkg/validator"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,27 +23,40 @@ type UserHandler interface {
 	Create(c *gin.Context)
 	Get(c *gin.Context)
 	GetMe(c *gin.Context)
+	List(c *gin.Context)
+	Search(c *gin.Context)
+	Update(c *gin.Context)
+	UpdateMe(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	Delete(c *gin.Context)
+	Restore(c *gin.Context)
+	Suspend(c *gin.Context)
+	Disable(c *gin.Context)
+	Reactivate(c *gin.Context)
 }
 
 type userHandler struct {
-	service service.UserService
+	service      service.UserService
+	verification service.EmailVerificationService
 }
 
-func NewUserHandler(service service.UserService) UserHandler {
+func NewUserHandler(service service.UserService, verification service.EmailVerificationService) UserHandler {
 	return &userHandler{
-		service: service,
+		service:      service,
+		verification: verification,
 	}
 }
 
 // Create godoc
 // @Summary Create a new user
-// @Description Create a new user with the provided information
+// @Description Create a new user with the provided information. A link to confirm the email address is sent to it.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param user body requests.UserCreateRequest true "User information"
 // @Success 201 {object} BaseResponse{data=responses.UserResponse} "User created successfully"
 // @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Failure 409 {object} BaseResponse "Email already in use"
 // @Failure 500 {object} BaseResponse "Internal server error"
 // @Router /users [post]
 func (h *userHandler) Create(c *gin.Context) {
@@ -46,35 +65,34 @@ func (h *userHandler) Create(c *gin.Context) {
 		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
 		return
 	}
-
-	user := req.ToModel()
-	validationErrs, err := h.service.CreateUser(user)
-	if err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), []interface{}{err.Error()})
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
 		return
 	}
-	if len(validationErrs) > 0 {
-		errs := make([]interface{}, len(validationErrs))
-		for i, v := range validationErrs {
-			errs[i] = v
-		}
-		NewErrorResponse(c, http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errs)
+
+	user := req.ToModel()
+	if err := h.service.CreateUser(user, auditActor(c)); err != nil {
+		NewServiceErrorResponse(c, err)
 		return
 	}
 
+	// A failed email is logged by the service; the user can ask for a new one
+	_ = h.verification.SendVerification(user)
+
 	response := responses.UserResponseFromModel(user)
 	NewSuccessResponse(c, http.StatusCreated, "User created successfully", response)
 }
 
 // Get godoc
 // @Summary Get a user by ID
-// @Description Get user details by their ID
+// @Description Get user details by their ID. Users get their own record and admin and support staff anyone's; other users only get the public profile (id, name and avatar_urls), or 404 when public profiles are disabled.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} BaseResponse{data=responses.UserResponse} "User retrieved successfully"
 // @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
 // @Failure 404 {object} BaseResponse "User not found"
 // @Router /users/{id} [get]
 func (h *userHandler) Get(c *gin.Context) {
@@ -84,13 +102,25 @@ func (h *userHandler) Get(c *gin.Context) {
 		return
 	}
 
+	access := currentAccess(c)
+	if access == policy.AccessNone {
+		NewServiceErrorResponse(c, service.ErrUserNotFound)
+		return
+	}
+
 	user, err := h.service.GetUser(uint(id))
 	if err != nil {
-		NewErrorResponse(c, http.StatusNotFound, "User not found", []interface{}{err.Error()})
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	if access == policy.AccessPublic {
+		NewSuccessResponse(c, http.StatusOK, "User retrieved successfully", responses.PublicUserResponseFromModel(user))
 		return
 	}
 
 	response := responses.UserResponseFromModel(user)
+	c.Header("ETag", userETag(user.Version))
 	NewSuccessResponse(c, http.StatusOK, "User retrieved successfully", response)
 }
 
@@ -104,24 +134,430 @@ func (h *userHandler) Get(c *gin.Context) {
 // @Failure 401 {object} BaseResponse "Unauthorized"
 // @Router /users/me [get]
 func (h *userHandler) GetMe(c *gin.Context) {
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	userDetails, err := h.service.GetUser(authenticatedUser.ID)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	response := responses.UserResponseFromModel(userDetails)
+	c.Header("ETag", userETag(userDetails.Version))
+	NewSuccessResponse(c, http.StatusOK, "User retrieved successfully", response)
+}
+
+// List godoc
+// @Summary List users
+// @Description List users with cursor-based pagination, filtering and sorting. Pass the returned next_cursor to fetch the following page with the same filters and sort. Only the members of the organization the token acts in are listed; users without an organization only see themselves, admin and support staff see everyone.
+// @Tags users
+// @Produce json
+// @Param limit query int false "Page size (1-100)" default(20)
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param name query string false "Case-insensitive substring of the name"
+// @Param email query string false "Case-insensitive substring of the email"
+// @Param created_after query string false "Only users created at or after this RFC 3339 time"
+// @Param created_before query string false "Only users created before this RFC 3339 time"
+// @Param sort query string false "Sort column, prefixed with - for descending" Enums(created_at, -created_at, name, -name, email, -email, id, -id)
+// @Param include_total query bool false "Also count all matching users"
+// @Success 200 {object} BaseResponse{data=[]responses.UserResponse} "Users retrieved successfully"
+// @Failure 400 {object} BaseResponse "Invalid query"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Router /users [get]
+func (h *userHandler) List(c *gin.Context) {
+	var req requests.UserListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid query", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	scope, ok := currentScope(c)
+	if !ok {
+		return
+	}
+
+	query := req.ToQuery()
+	query.Scope = scope
+	page, err := h.service.ListUsers(query)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	pagination := &Pagination{
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+		Limit:      page.Limit,
+		Total:      page.Total,
+	}
+
+	NewPaginatedResponse(c, http.StatusOK, "Users retrieved successfully", responses.UserResponsesFromModels(page.Users), pagination)
+}
+
+// Search godoc
+// @Summary Search users
+// @Description Find users by partial or misspelled name or email, ranked by relevance. Matches are wrapped in <mark> tags in the highlight fields. Results are limited to the users the caller may list.
+// @Tags users
+// @Produce json
+// @Param q query string true "Search query (2-100 characters)"
+// @Param limit query int false "Maximum number of results (1-50)" default(20)
+// @Success 200 {object} BaseResponse{data=[]responses.UserSearchResultResponse} "Users found successfully"
+// @Failure 400 {object} BaseResponse "Invalid query"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Router /users/search [get]
+func (h *userHandler) Search(c *gin.Context) {
+	var req requests.UserSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid query", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	scope, ok := currentScope(c)
+	if !ok {
+		return
+	}
+
+	results, err := h.service.SearchUsers(scope, req.Query, req.Limit)
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Users found successfully", responses.UserSearchResultResponsesFromResults(results))
+}
+
+// Update godoc
+// @Summary Update a user
+// @Description Partially update a user's name and/or email. The expected version must be sent in the If-Match header (the ETag returned by GET) or in the version field.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param user body requests.UserUpdateRequest true "Fields to update"
+// @Success 200 {object} BaseResponse{data=responses.UserResponse} "User updated successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Failure 409 {object} BaseResponse "Version conflict or email already in use"
+// @Failure 428 {object} BaseResponse "Missing expected version"
+// @Router /users/{id} [patch]
+func (h *userHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid ID", []interface{}{err.Error()})
+		return
+	}
+
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if authenticatedUser.ID != uint(id) {
+		NewErrorResponse(c, http.StatusForbidden, "Forbidden", []interface{}{"you can only update your own user"})
+		return
+	}
+
+	h.update(c, uint(id))
+}
+
+// UpdateMe godoc
+// @Summary Update logged in user
+// @Description Partially update the currently logged in user. The expected version must be sent in the If-Match header (the ETag returned by GET) or in the version field.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param user body requests.UserUpdateRequest true "Fields to update"
+// @Success 200 {object} BaseResponse{data=responses.UserResponse} "User updated successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 409 {object} BaseResponse "Version conflict or email already in use"
+// @Failure 428 {object} BaseResponse "Missing expected version"
+// @Router /users/me [patch]
+func (h *userHandler) UpdateMe(c *gin.Context) {
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	h.update(c, authenticatedUser.ID)
+}
+
+func (h *userHandler) update(c *gin.Context, id uint) {
+	var req requests.UserUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	version, ok, err := expectedVersion(c, req.Version)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid If-Match header", []interface{}{err.Error()})
+		return
+	}
+	if !ok {
+		NewErrorResponse(c, http.StatusPreconditionRequired, "Missing expected version", []interface{}{"send the user's ETag in If-Match or its version in the body"})
+		return
+	}
+
+	user, err := h.service.UpdateUser(id, req.ToUpdate(), version, auditActor(c))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	if req.Email != nil && !user.EmailVerified() {
+		// A failed email is logged by the service; the user can ask for a new one
+		_ = h.verification.SendVerification(user)
+	}
+
+	response := responses.UserResponseFromModel(user)
+	c.Header("ETag", userETag(user.Version))
+	NewSuccessResponse(c, http.StatusOK, "User updated successfully", response)
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the password of the currently logged in user. Every token issued before the change, including the one used for this request, stops being accepted, so the client has to log in again.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param password body requests.PasswordChangeRequest true "Current and new password"
+// @Success 200 {object} BaseResponse "Password changed successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload, wrong current password or new password rejected by the policy"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Router /users/me/password [post]
+func (h *userHandler) ChangePassword(c *gin.Context) {
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req requests.PasswordChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	if err := h.service.ChangePassword(authenticatedUser.ID, req.CurrentPassword, req.NewPassword, auditActor(c)); err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "Password changed successfully, please log in again", nil)
+}
+
+// Delete godoc
+// @Summary Delete a user
+// @Description Soft delete a user. The user can be restored by an admin until it is purged after the retention period.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} BaseResponse "User deleted successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Failure 409 {object} BaseResponse "User is the last owner of an organization"
+// @Router /users/{id} [delete]
+func (h *userHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid ID", []interface{}{err.Error()})
+		return
+	}
+
+	authenticatedUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if authenticatedUser.ID != uint(id) {
+		NewErrorResponse(c, http.StatusForbidden, "Forbidden", []interface{}{"you can only delete your own user"})
+		return
+	}
+
+	if err := h.service.DeleteUser(uint(id), auditActor(c)); err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, "User deleted successfully", nil)
+}
+
+// Restore godoc
+// @Summary Restore a deleted user
+// @Description Undo the soft delete of a user that hasn't been purged yet
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} BaseResponse{data=responses.UserResponse} "User restored successfully"
+// @Failure 400 {object} BaseResponse "Invalid ID"
+// @Failure 404 {object} BaseResponse "Deleted user not found"
+// @Failure 409 {object} BaseResponse "Email already in use"
+// @Router /admin/users/{id}/restore [post]
+func (h *userHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid ID", []interface{}{err.Error()})
+		return
+	}
+
+	user, err := h.service.RestoreUser(uint(id), auditActor(c))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	response := responses.UserResponseFromModel(user)
+	NewSuccessResponse(c, http.StatusOK, "User restored successfully", response)
+}
+
+// Suspend godoc
+// @Summary Suspend a user
+// @Description Suspend a user for a while, such as for abuse, without deleting them. Suspended users can't log in and their tokens stop working within seconds. Admins cannot suspend themselves.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body requests.UserStatusRequest true "Why the user is suspended"
+// @Success 200 {object} BaseResponse{data=responses.UserStatusResponse} "User suspended successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload or missing reason"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden, or the user is an admin or support and the caller can't manage roles"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Failure 409 {object} BaseResponse "User is already suspended or is the caller"
+// @Router /admin/users/{id}/suspend [post]
+func (h *userHandler) Suspend(c *gin.Context) {
+	h.setStatus(c, model.UserStatusSuspended, "User suspended successfully")
+}
+
+// Disable godoc
+// @Summary Disable a user
+// @Description Close a user's account for good without deleting it. Disabled users can't log in and their tokens stop working within seconds. Admins cannot disable themselves.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body requests.UserStatusRequest true "Why the user is disabled"
+// @Success 200 {object} BaseResponse{data=responses.UserStatusResponse} "User disabled successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload or missing reason"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden, or the user is an admin or support and the caller can't manage roles"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Failure 409 {object} BaseResponse "User is already disabled or is the caller"
+// @Router /admin/users/{id}/disable [post]
+func (h *userHandler) Disable(c *gin.Context) {
+	h.setStatus(c, model.UserStatusDisabled, "User disabled successfully")
+}
+
+// Reactivate godoc
+// @Summary Reactivate a user
+// @Description Let a suspended or disabled user log in again. The reason is optional.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body requests.UserStatusRequest false "Why the user is reactivated"
+// @Success 200 {object} BaseResponse{data=responses.UserStatusResponse} "User reactivated successfully"
+// @Failure 400 {object} BaseResponse "Invalid request payload"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden, or the user is an admin or support and the caller can't manage roles"
+// @Failure 404 {object} BaseResponse "User not found"
+// @Failure 409 {object} BaseResponse "User is already active or is the caller"
+// @Router /admin/users/{id}/reactivate [post]
+func (h *userHandler) Reactivate(c *gin.Context) {
+	h.setStatus(c, model.UserStatusActive, "User reactivated successfully")
+}
+
+// setStatus sets the status of the user in the path, with the reason from the
+// optional request body
+func (h *userHandler) setStatus(c *gin.Context, status model.UserStatus, message string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid ID", []interface{}{err.Error()})
+		return
+	}
+
+	var req requests.UserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	user, err := h.service.SetStatus(uint(id), status, req.Reason, auditActor(c))
+	if err != nil {
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	NewSuccessResponse(c, http.StatusOK, message, responses.UserStatusResponseFromModel(user))
+}
+
+// currentUser returns the authenticated user set by the JWT middleware. If
+// there is none, an error response has already been written.
+func currentUser(c *gin.Context) (*model.User, bool) {
 	user, exists := c.Get(identityKey)
 	if !exists {
 		NewErrorResponse(c, http.StatusUnauthorized, "Unauthorized", nil)
-		return
+		return nil, false
 	}
 
 	authenticatedUser, ok := user.(*model.User)
 	if !ok {
 		NewErrorResponse(c, http.StatusUnauthorized, "Invalid user data", nil)
-		return
+		return nil, false
 	}
 
-	userDetails, err := h.service.GetUser(authenticatedUser.ID)
+	return authenticatedUser, true
+}
+
+// userETag formats a user version as a strong entity tag
+func userETag(version uint) string {
+	return fmt.Sprintf("%q", strconv.FormatUint(uint64(version), 10))
+}
+
+// expectedVersion reads the version an update is based on from If-Match,
+// falling back to the version in the body
+func expectedVersion(c *gin.Context, bodyVersion *uint) (uint, bool, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		if bodyVersion == nil {
+			return 0, false, nil
+		}
+		return *bodyVersion, true, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+	version, err := strconv.ParseUint(tag, 10, 32)
 	if err != nil {
-		NewErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve user details", []interface{}{err.Error()})
-		return
+		return 0, false, fmt.Errorf("expected an ETag returned by this API, got %s", ifMatch)
 	}
 
-	response := responses.UserResponseFromModel(userDetails)
-	NewSuccessResponse(c, http.StatusOK, "User retrieved successfully", response)
+	return uint(version), true, nil
 }
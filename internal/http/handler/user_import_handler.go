@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"example/internal/http/handler/requests"
+	"example/internal/http/handler/responses"
+	"example/internal/service"
+	"example/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserImportHandler defines the interface for bulk user import handler operations
+type UserImportHandler interface {
+	Import(c *gin.Context)
+}
+
+type userImportHandler struct {
+	service service.UserImportService
+}
+
+func NewUserImportHandler(service service.UserImportService) UserImportHandler {
+	return &userImportHandler{
+		service: service,
+	}
+}
+
+// Import godoc
+// @Summary Import users
+// @Description Create users from a CSV or NDJSON file sent as the request body. CSV files need a header with name, email and password columns; NDJSON lines have the fields of a user creation request. Every row is validated like a user creation request and reported on separately, so invalid rows don't stop the others. With dry_run nothing is saved. If the import stops part way once users were created, the report is still returned, with the reason in error; rows missing from it weren't imported.
+// @Tags admin
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "File format, defaults to the one of the Content-Type" Enums(csv, ndjson)
+// @Param dry_run query bool false "Only validate the rows"
+// @Success 200 {object} BaseResponse{data=responses.UserImportResponse} "Users imported successfully"
+// @Failure 400 {object} BaseResponse "Unknown format or unreadable file"
+// @Failure 401 {object} BaseResponse "Unauthorized"
+// @Failure 403 {object} BaseResponse "Forbidden"
+// @Failure 413 {object} BaseResponse "Import file too large"
+// @Router /admin/users/import [post]
+func (h *userImportHandler) Import(c *gin.Context) {
+	var req requests.UserImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid query", []interface{}{err.Error()})
+		return
+	}
+	if validationErrs := validator.ValidateStruct(req); len(validationErrs) > 0 {
+		NewValidationErrorResponse(c, validationErrs)
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = requests.UserImportFormatFromContentType(c.ContentType())
+	}
+	if format == "" {
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{"send text/csv or application/x-ndjson, or set the format parameter"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.service.MaxBytes())
+	source, err := requests.NewUserImportSource(c.Request.Body, format)
+	if err != nil {
+		if importTooLarge(c, err) {
+			return
+		}
+		NewErrorResponse(c, http.StatusBadRequest, "Invalid request payload", []interface{}{err.Error()})
+		return
+	}
+
+	report, err := h.service.Import(c.Request.Context(), source, req.DryRun, auditActor(c))
+	if err != nil {
+		if importTooLarge(c, err) {
+			return
+		}
+		NewServiceErrorResponse(c, err)
+		return
+	}
+
+	message := "Users imported successfully"
+	if req.DryRun {
+		message = "Users validated successfully"
+	}
+	NewSuccessResponse(c, http.StatusOK, message, responses.UserImportResponseFromReport(report))
+}
+
+// importTooLarge responds with 413 if err comes from a body over the import limit
+func importTooLarge(c *gin.Context, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return false
+	}
+	NewErrorResponse(c, http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge), []interface{}{"import file is too large"})
+	return true
+}
@@ -0,0 +1,74 @@
+package job
+
+import (
+	"context"
+	"example/internal/config"
+	"example/internal/service"
+	"example/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultRetention protects deleted users if no retention is configured
+const defaultRetention = 30 * 24 * time.Hour
+
+// UserPurge periodically removes users that have been soft deleted for
+// longer than the configured retention period
+type UserPurge struct {
+	userService service.UserService
+	retention   time.Duration
+	interval    time.Duration
+	logger      *zap.Logger
+}
+
+func NewUserPurge(userService service.UserService, cfg *config.UsersConfig) *UserPurge {
+	retention := cfg.DeletedRetention
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+
+	return &UserPurge{
+		userService: userService,
+		retention:   retention,
+		interval:    cfg.PurgeInterval,
+		logger:      logger.GetLogger().With(zap.String("component", "user-purge")),
+	}
+}
+
+// Run purges once right away and then on every interval until ctx is done.
+// It returns immediately if the interval is not positive.
+func (j *UserPurge) Run(ctx context.Context) {
+	if j.interval <= 0 {
+		j.logger.Info("User purge job disabled")
+		return
+	}
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce purges expired users and returns how many were removed
+func (j *UserPurge) RunOnce() (int64, error) {
+	purged, err := j.userService.PurgeDeletedUsers(j.retention)
+	if err != nil {
+		j.logger.Error("Failed to purge deleted users", zap.Error(err))
+		return 0, err
+	}
+
+	j.logger.Info("Purged deleted users",
+		zap.Int64("purged", purged),
+		zap.Duration("retention", j.retention),
+	)
+	return purged, nil
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// AuditAction is what an audit event records
+type AuditAction string
+
+// Audited actions. User changes are recorded in the same transaction as the
+// change itself.
+const (
+	AuditUserCreated         AuditAction = "user.created"
+	AuditUserUpdated         AuditAction = "user.updated"
+	AuditUserPasswordChanged AuditAction = "user.password_changed"
+	AuditUserEmailVerified   AuditAction = "user.email_verified"
+	AuditUserAvatarChanged   AuditAction = "user.avatar_changed"
+	AuditUserDeleted         AuditAction = "user.deleted"
+	AuditUserRestored        AuditAction = "user.restored"
+	AuditUserErased          AuditAction = "user.erased"
+	AuditRoleGranted         AuditAction = "user.role_granted"
+	AuditRoleRevoked         AuditAction = "user.role_revoked"
+	AuditUserUnlocked        AuditAction = "user.unlocked"
+	AuditUserStatusChanged   AuditAction = "user.status_changed"
+	AuditLoginSucceeded      AuditAction = "auth.login_succeeded"
+	AuditLoginFailed         AuditAction = "auth.login_failed"
+	AuditTokenRefreshed      AuditAction = "auth.token_refreshed"
+	AuditAccountLocked       AuditAction = "auth.account_locked"
+)
+
+// AuditRedacted stands in for the values of secret and personal fields in
+// changes, which are only recorded as changed
+const AuditRedacted = "[redacted]"
+
+// AuditActor is who made a change and from where. UserID is nil for anonymous
+// requests, such as signing up or resetting a forgotten password, and for
+// changes made from the command line.
+type AuditActor struct {
+	UserID    *uint
+	IP        string
+	UserAgent string
+}
+
+// AuditChange is the value of a field before and after a change
+type AuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AuditChanges maps each changed field to its change. It is stored as JSON.
+type AuditChanges map[string]AuditChange
+
+// AuditMetadata holds details of an event that aren't field changes, such as
+// why a login failed. It is stored as JSON.
+type AuditMetadata map[string]string
+
+// AuditEvent is an entry of the append-only audit log
+type AuditEvent struct {
+	ID         uint64      `json:"id" gorm:"primarykey"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Action     AuditAction `json:"action"`
+	// ActorID is who made the change, TargetID the user it was made to
+	ActorID   *uint         `json:"actor_id"`
+	TargetID  *uint         `json:"target_id"`
+	IP        string        `json:"ip"`
+	UserAgent string        `json:"user_agent"`
+	Changes   AuditChanges  `json:"changes" gorm:"type:jsonb"`
+	Metadata  AuditMetadata `json:"metadata" gorm:"type:jsonb"`
+}
+
+// NewAuditEvent starts an event for the action made by actor to the target
+// user, 0 for none
+func NewAuditEvent(action AuditAction, actor AuditActor, targetID uint) *AuditEvent {
+	event := &AuditEvent{
+		OccurredAt: time.Now(),
+		Action:     action,
+		ActorID:    actor.UserID,
+		IP:         actor.IP,
+		UserAgent:  actor.UserAgent,
+	}
+	if targetID != 0 {
+		event.TargetID = &targetID
+	}
+	return event
+}
+
+func (c AuditChanges) Value() (driver.Value, error) {
+	return marshalAuditJSON(c, len(c) == 0)
+}
+
+func (c *AuditChanges) Scan(value interface{}) error {
+	return unmarshalAuditJSON(value, c)
+}
+
+func (m AuditMetadata) Value() (driver.Value, error) {
+	return marshalAuditJSON(m, len(m) == 0)
+}
+
+func (m *AuditMetadata) Scan(value interface{}) error {
+	return unmarshalAuditJSON(value, m)
+}
+
+func marshalAuditJSON(v interface{}, empty bool) (driver.Value, error) {
+	if empty {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func unmarshalAuditJSON(value interface{}, v interface{}) error {
+	switch raw := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(raw, v)
+	case string:
+		return json.Unmarshal([]byte(raw), v)
+	default:
+		return errors.New("unsupported audit JSON value")
+	}
+}
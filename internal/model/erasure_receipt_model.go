@@ -0,0 +1,57 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErasureReceipt records that a user's personal data was erased. Receipts
+// form a hash chain: each hash covers the receipt's fields and the hash of
+// the receipt before it, so editing or removing a receipt breaks every hash
+// after it.
+type ErasureReceipt struct {
+	ID     uint `gorm:"primarykey"`
+	UserID uint `gorm:"not null"`
+	// EmailHash is the HMAC-SHA256 of the erased email, lowercased, keyed
+	// with a server secret. The erasure can be confirmed to whoever still
+	// knows the address, but the hash can't be matched against a list of
+	// emails without the key.
+	EmailHash string `gorm:"not null"`
+	// RequestedBy is who asked for the erasure: the user themselves or an
+	// admin, nil when run from the command line
+	RequestedBy *uint
+	ErasedAt    time.Time `gorm:"not null"`
+	// PrevHash is the hash of the previous receipt, empty for the first one
+	PrevHash string `gorm:"not null"`
+	Hash     string `gorm:"not null;uniqueIndex"`
+}
+
+// ComputeHash returns the hash the receipt should have given its fields and
+// PrevHash. ErasedAt is hashed at microsecond precision, the precision it is
+// stored at.
+func (r *ErasureReceipt) ComputeHash() string {
+	requestedBy := ""
+	if r.RequestedBy != nil {
+		requestedBy = strconv.FormatUint(uint64(*r.RequestedBy), 10)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		r.PrevHash,
+		strconv.FormatUint(uint64(r.UserID), 10),
+		r.EmailHash,
+		requestedBy,
+		r.ErasedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+	}, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// ErasureEmailHash is the hash of an email stored on erasure receipts
+func ErasureEmailHash(key []byte, email string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.ToLower(email)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
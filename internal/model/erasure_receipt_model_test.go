@@ -0,0 +1,62 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestErasureReceiptComputeHash(t *testing.T) {
+	admin := uint(1)
+	other := uint(2)
+	erasedAt := time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC)
+	receipt := ErasureReceipt{
+		ID:          7,
+		UserID:      42,
+		EmailHash:   "abc",
+		RequestedBy: &admin,
+		ErasedAt:    erasedAt,
+		PrevHash:    "def",
+	}
+	hash := receipt.ComputeHash()
+
+	tests := []struct {
+		name   string
+		modify func(r *ErasureReceipt)
+		same   bool
+	}{
+		{name: "unchanged", modify: func(r *ErasureReceipt) {}, same: true},
+		{name: "ID isn't hashed", modify: func(r *ErasureReceipt) { r.ID = 8 }, same: true},
+		{name: "stored hash isn't hashed", modify: func(r *ErasureReceipt) { r.Hash = "xyz" }, same: true},
+		{name: "other time zone", modify: func(r *ErasureReceipt) { r.ErasedAt = erasedAt.In(time.FixedZone("CET", 3600)) }, same: true},
+		{name: "below microseconds", modify: func(r *ErasureReceipt) { r.ErasedAt = erasedAt.Add(999) }, same: true},
+		{name: "user", modify: func(r *ErasureReceipt) { r.UserID = 43 }},
+		{name: "email hash", modify: func(r *ErasureReceipt) { r.EmailHash = "abd" }},
+		{name: "requester", modify: func(r *ErasureReceipt) { r.RequestedBy = &other }},
+		{name: "no requester", modify: func(r *ErasureReceipt) { r.RequestedBy = nil }},
+		{name: "erased at", modify: func(r *ErasureReceipt) { r.ErasedAt = erasedAt.Add(time.Microsecond) }},
+		{name: "previous hash", modify: func(r *ErasureReceipt) { r.PrevHash = "" }},
+		{name: "fields shifted across the separator", modify: func(r *ErasureReceipt) { r.PrevHash, r.UserID = "def\n4", 2 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := receipt
+			tt.modify(&modified)
+			if same := modified.ComputeHash() == hash; same != tt.same {
+				t.Errorf("ComputeHash() unchanged is %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestErasureEmailHash(t *testing.T) {
+	key := []byte("key")
+	hash := ErasureEmailHash(key, "john@example.com")
+
+	if got := ErasureEmailHash(key, "John@Example.com"); got != hash {
+		t.Errorf("ErasureEmailHash() differs by case: %s, want %s", got, hash)
+	}
+	if got := ErasureEmailHash([]byte("other"), "john@example.com"); got == hash {
+		t.Error("ErasureEmailHash() is the same under another key")
+	}
+}
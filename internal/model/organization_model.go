@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// Organization is a customer organization grouping users
+type Organization struct {
+	ID        uint   `json:"id" gorm:"primarykey"`
+	Name      string `json:"name" gorm:"not null"`
+	CreatedBy *uint  `json:"created_by"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OrgRole is the role of a member within an organization. It is unrelated to
+// the API wide Role.
+type OrgRole string
+
+const (
+	// OrgRoleOwner manages the organization, including its owners
+	OrgRoleOwner OrgRole = "owner"
+	// OrgRoleAdmin manages members and their roles, except owners
+	OrgRoleAdmin OrgRole = "admin"
+	// OrgRoleMember can see the organization and its members
+	OrgRoleMember OrgRole = "member"
+)
+
+// Valid reports whether r is a known organization role
+func (r OrgRole) Valid() bool {
+	switch r {
+	case OrgRoleOwner, OrgRoleAdmin, OrgRoleMember:
+		return true
+	}
+	return false
+}
+
+// CanManageMembers reports whether the role may invite and remove members
+// and change their roles
+func (r OrgRole) CanManageMembers() bool {
+	return r == OrgRoleOwner || r == OrgRoleAdmin
+}
+
+// Membership links a user to an organization they joined. Until then they
+// only have an Invitation.
+type Membership struct {
+	OrganizationID uint       `json:"organization_id" gorm:"primaryKey"`
+	UserID         uint       `json:"user_id" gorm:"primaryKey"`
+	Role           OrgRole    `json:"role" gorm:"not null"`
+	InvitedBy      *uint      `json:"invited_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+
+	Organization *Organization `json:"organization,omitempty" gorm:"foreignKey:OrganizationID"`
+	User         *User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// Active reports whether the invitation was accepted
+func (m *Membership) Active() bool {
+	return m.AcceptedAt != nil
+}
+
+// Invitation invites whoever owns Email to join an organization. It is
+// addressed to the email rather than a user, so inviting works the same
+// whether the email has an account or not.
+type Invitation struct {
+	OrganizationID uint      `json:"organization_id" gorm:"primaryKey"`
+	Email          string    `json:"email" gorm:"primaryKey"`
+	Role           OrgRole   `json:"role" gorm:"not null"`
+	InvitedBy      *uint     `json:"invited_by"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	Organization *Organization `json:"organization,omitempty" gorm:"foreignKey:OrganizationID"`
+}
+
+// TableName names the table after the organizations it invites to
+func (Invitation) TableName() string {
+	return "organization_invitations"
+}
@@ -0,0 +1,100 @@
+package model
+
+import "time"
+
+// Role is a set of permissions granted to a user
+type Role string
+
+// Roles known to the API. Every user implicitly has RoleUser; the others are
+// granted explicitly and stored in user_roles.
+const (
+	RoleUser    Role = "user"
+	RoleAdmin   Role = "admin"
+	RoleSupport Role = "support"
+)
+
+// Permission is an action on a kind of resource, checked by route middleware
+type Permission string
+
+const (
+	PermissionUsersRead    Permission = "users:read"
+	PermissionCacheRead    Permission = "cache:read"
+	PermissionCacheWrite   Permission = "cache:write"
+	PermissionUsersRestore Permission = "users:restore"
+	PermissionUsersImport  Permission = "users:import"
+	PermissionUsersExport  Permission = "users:export"
+	PermissionUsersErase   Permission = "users:erase"
+	PermissionUsersUnlock  Permission = "users:unlock"
+	PermissionUsersSuspend Permission = "users:suspend"
+	PermissionRolesRead    Permission = "roles:read"
+	PermissionRolesManage  Permission = "roles:manage"
+	PermissionAuditRead    Permission = "audit:read"
+)
+
+// rolePermissions lists what each role may do. Admins may do everything.
+// Cached users include password hashes, so the cache stays admin only.
+var rolePermissions = map[Role][]Permission{
+	RoleUser: {},
+	RoleSupport: {
+		PermissionUsersRead,
+		PermissionUsersRestore,
+		PermissionUsersUnlock,
+		PermissionUsersSuspend,
+		PermissionRolesRead,
+		PermissionAuditRead,
+	},
+}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleAdmin, RoleSupport:
+		return true
+	}
+	return false
+}
+
+// Can reports whether the role grants the permission
+func (r Role) Can(permission Permission) bool {
+	if r == RoleAdmin {
+		return true
+	}
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether any of roles is one of wanted
+func HasRole(roles []Role, wanted ...Role) bool {
+	for _, role := range roles {
+		for _, w := range wanted {
+			if role == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether any of roles grants the permission
+func HasPermission(roles []Role, permission Permission) bool {
+	for _, role := range roles {
+		if role.Can(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// UserRole is a role explicitly granted to a user
+type UserRole struct {
+	UserID    uint `gorm:"primaryKey"`
+	Role      Role `gorm:"primaryKey"`
+	GrantedAt time.Time
+	// GrantedBy is the admin who granted the role, nil when granted from the
+	// command line
+	GrantedBy *uint
+}
@@ -1,12 +1,101 @@
 package model
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// UserStatus tells whether a user may log in. Suspended and disabled users
+// can't log in and their tokens stop working; suspension is meant to be
+// temporary, while disabling is for accounts that stay closed.
+type UserStatus string
+
+const (
+	UserStatusActive    UserStatus = "active"
+	UserStatusSuspended UserStatus = "suspended"
+	UserStatusDisabled  UserStatus = "disabled"
+)
+
+// Valid reports whether the status is one of the known statuses
+func (s UserStatus) Valid() bool {
+	switch s {
+	case UserStatusActive, UserStatusSuspended, UserStatusDisabled:
+		return true
+	}
+	return false
+}
+
 type User struct {
 	gorm.Model
 	Name     string `json:"name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// Version is incremented on every update and used for optimistic locking
+	Version uint `json:"version" gorm:"not null;default:1"`
+	// PasswordChangedAt invalidates every token issued before it
+	PasswordChangedAt *time.Time `json:"password_changed_at"`
+	// EmailVerifiedAt is when the user confirmed owning the email, nil until
+	// then and again after the email changes
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// AvatarKey is the blob store prefix the avatar thumbnails are stored
+	// under, and AvatarURL the public URL of that prefix. Both are nil when
+	// the user has no avatar.
+	AvatarKey *string `json:"avatar_key"`
+	AvatarURL *string `json:"avatar_url"`
+	// ErasedAt is when the user's personal data was erased. Erased users are
+	// soft deleted and can't be restored.
+	ErasedAt *time.Time `json:"erased_at"`
+	// Status is whether the user may log in. StatusReason is why an admin
+	// last changed it and StatusChangedAt when, both nil until then.
+	Status          UserStatus `json:"status" gorm:"not null;default:active"`
+	StatusReason    *string    `json:"status_reason"`
+	StatusChangedAt *time.Time `json:"status_changed_at"`
+	// LastLoginAt is when the user last logged in, nil if they never did
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// AvatarSizes are the square thumbnail sizes, in pixels, avatars are stored at
+var AvatarSizes = []int{64, 128, 256}
+
+// AvatarFile is the name of the thumbnail of the given size under the avatar
+// prefix
+func AvatarFile(size int) string {
+	return fmt.Sprintf("%d.png", size)
+}
+
+// AvatarURLs maps each thumbnail size to its public URL, nil when the user
+// has no avatar
+func (u *User) AvatarURLs() map[string]string {
+	if u.AvatarURL == nil {
+		return nil
+	}
+
+	urls := make(map[string]string, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		urls[strconv.Itoa(size)] = *u.AvatarURL + "/" + AvatarFile(size)
+	}
+	return urls
+}
+
+// EmailVerified reports whether the user confirmed their current email
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// Active reports whether the user may log in. Users cached before the status
+// column existed have none and count as active.
+func (u *User) Active() bool {
+	return u.Status == UserStatusActive || u.Status == ""
+}
+
+// PasswordChangedUnix returns PasswordChangedAt as a Unix timestamp, or 0 if
+// the password was never changed
+func (u *User) PasswordChangedUnix() int64 {
+	if u.PasswordChangedAt == nil {
+		return 0
+	}
+	return u.PasswordChangedAt.Unix()
 }
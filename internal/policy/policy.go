@@ -0,0 +1,95 @@
+// Package policy decides what an authenticated user may see of a resource.
+// Policies are plain values built from rules, so they can be evaluated and
+// tested without an HTTP request; the handler package attaches them to routes.
+package policy
+
+import "example/internal/model"
+
+// Access is how much of a resource a subject may see
+type Access int
+
+const (
+	// AccessNone hides the resource, it is reported as not found so callers
+	// can't tell it exists
+	AccessNone Access = iota
+	// AccessPublic allows the public projection of the resource
+	AccessPublic
+	// AccessFull allows the whole resource
+	AccessFull
+)
+
+func (a Access) String() string {
+	switch a {
+	case AccessPublic:
+		return "public"
+	case AccessFull:
+		return "full"
+	}
+	return "none"
+}
+
+// Subject is the authenticated user making the request
+type Subject struct {
+	UserID uint
+	Roles  []model.Role
+}
+
+// Resource is what the request is about
+type Resource struct {
+	// OwnerID is the user the resource belongs to; for users, themselves
+	OwnerID uint
+}
+
+// Condition reports whether a rule applies
+type Condition func(subject Subject, resource Resource) bool
+
+// Rule grants access when its condition holds
+type Rule struct {
+	Name   string
+	When   Condition
+	Access Access
+}
+
+// Policy is an ordered list of rules. The first rule whose condition holds
+// decides the access; Otherwise applies when none does.
+type Policy struct {
+	Name      string
+	Rules     []Rule
+	Otherwise Access
+}
+
+// Evaluate returns the access the subject has to the resource
+func (p Policy) Evaluate(subject Subject, resource Resource) Access {
+	access, _ := p.Explain(subject, resource)
+	return access
+}
+
+// Explain returns the access the subject has to the resource and the name of
+// the rule that granted it, empty when Otherwise applied
+func (p Policy) Explain(subject Subject, resource Resource) (Access, string) {
+	for _, rule := range p.Rules {
+		if rule.When(subject, resource) {
+			return rule.Access, rule.Name
+		}
+	}
+	return p.Otherwise, ""
+}
+
+// Self holds when the subject owns the resource
+func Self(subject Subject, resource Resource) bool {
+	return subject.UserID != 0 && subject.UserID == resource.OwnerID
+}
+
+// HasRole holds when the subject has any of the roles
+func HasRole(roles ...model.Role) Condition {
+	return func(subject Subject, _ Resource) bool {
+		return model.HasRole(subject.Roles, roles...)
+	}
+}
+
+// HasPermission holds when one of the subject's roles grants the permission
+func HasPermission(permission model.Permission) Condition {
+	return func(subject Subject, _ Resource) bool {
+		return model.HasPermission(subject.Roles, permission)
+	}
+}
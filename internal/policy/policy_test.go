@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"testing"
+
+	"example/internal/config"
+	"example/internal/model"
+)
+
+func TestReadUser(t *testing.T) {
+	const ownerID = 42
+	owner := Resource{OwnerID: ownerID}
+
+	tests := []struct {
+		name           string
+		publicProfiles bool
+		subject        Subject
+		want           Access
+		wantRule       string
+	}{
+		{
+			name:     "self",
+			subject:  Subject{UserID: ownerID, Roles: []model.Role{model.RoleUser}},
+			want:     AccessFull,
+			wantRule: "self",
+		},
+		{
+			name:     "other user without public profiles",
+			subject:  Subject{UserID: 7, Roles: []model.Role{model.RoleUser}},
+			want:     AccessNone,
+			wantRule: "",
+		},
+		{
+			name:           "other user with public profiles",
+			publicProfiles: true,
+			subject:        Subject{UserID: 7, Roles: []model.Role{model.RoleUser}},
+			want:           AccessPublic,
+			wantRule:       "",
+		},
+		{
+			name:     "support reads any user",
+			subject:  Subject{UserID: 7, Roles: []model.Role{model.RoleUser, model.RoleSupport}},
+			want:     AccessFull,
+			wantRule: "users:read",
+		},
+		{
+			name:           "admin reads any user",
+			publicProfiles: true,
+			subject:        Subject{UserID: 7, Roles: []model.Role{model.RoleUser, model.RoleAdmin}},
+			want:           AccessFull,
+			wantRule:       "users:read",
+		},
+		{
+			name:     "anonymous subject is never self",
+			subject:  Subject{},
+			want:     AccessNone,
+			wantRule: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes := NewRoutes(&config.UsersConfig{PublicProfiles: tt.publicProfiles})
+
+			got, rule := routes.ReadUser.Explain(tt.subject, owner)
+			if got != tt.want {
+				t.Errorf("access = %s, want %s", got, tt.want)
+			}
+			if rule != tt.wantRule {
+				t.Errorf("rule = %q, want %q", rule, tt.wantRule)
+			}
+			if evaluated := routes.ReadUser.Evaluate(tt.subject, owner); evaluated != got {
+				t.Errorf("Evaluate = %s, Explain = %s", evaluated, got)
+			}
+		})
+	}
+}
+
+func TestSelf(t *testing.T) {
+	if !Self(Subject{UserID: 1}, Resource{OwnerID: 1}) {
+		t.Error("owner should be self")
+	}
+	if Self(Subject{UserID: 1}, Resource{OwnerID: 2}) {
+		t.Error("other user should not be self")
+	}
+	if Self(Subject{}, Resource{}) {
+		t.Error("zero subject should not be self of a zero resource")
+	}
+}
+
+func TestFirstMatchingRuleWins(t *testing.T) {
+	p := Policy{
+		Rules: []Rule{
+			{Name: "first", When: HasRole(model.RoleSupport), Access: AccessPublic},
+			{Name: "second", When: HasPermission(model.PermissionUsersRead), Access: AccessFull},
+		},
+		Otherwise: AccessNone,
+	}
+
+	got, rule := p.Explain(Subject{UserID: 1, Roles: []model.Role{model.RoleSupport}}, Resource{OwnerID: 2})
+	if got != AccessPublic || rule != "first" {
+		t.Errorf("got %s from %q, want public from \"first\"", got, rule)
+	}
+}
@@ -0,0 +1,33 @@
+package policy
+
+import (
+	"example/internal/config"
+	"example/internal/model"
+)
+
+// Routes holds the policy of each route that serves a resource which not
+// every authenticated user may see in full
+type Routes struct {
+	// ReadUser guards GET /api/users/:id
+	ReadUser Policy
+}
+
+func NewRoutes(cfg *config.UsersConfig) *Routes {
+	// Other users get the public profile when profiles are public, otherwise
+	// the user is hidden from them altogether
+	others := AccessNone
+	if cfg.PublicProfiles {
+		others = AccessPublic
+	}
+
+	return &Routes{
+		ReadUser: Policy{
+			Name: "read-user",
+			Rules: []Rule{
+				{Name: "self", When: Self, Access: AccessFull},
+				{Name: "users:read", When: HasPermission(model.PermissionUsersRead), Access: AccessFull},
+			},
+			Otherwise: others,
+		},
+	}
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"example/internal/model"
+	"example/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Page sizes used when an audit query doesn't set one or asks for too many
+const (
+	DefaultAuditListLimit = 50
+	MaxAuditListLimit     = 200
+)
+
+// AuditEventQuery describes a page of audit events to fetch, newest first.
+// Zero values don't filter.
+type AuditEventQuery struct {
+	ActorID  *uint
+	TargetID *uint
+	Action   model.AuditAction
+	// From and To bound the time the events occurred at, To exclusive
+	From *time.Time
+	To   *time.Time
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+	Limit  int
+}
+
+// AuditEventPage is a page of audit events returned by List
+type AuditEventPage struct {
+	Events     []model.AuditEvent
+	NextCursor string
+	HasMore    bool
+	Limit      int
+}
+
+// AuditRepository defines the interface for the audit log. User changes are
+// recorded by the repositories making them, in the same transaction; Record
+// is for events that don't change anything, such as logins.
+type AuditRepository interface {
+	Record(event *model.AuditEvent) error
+	List(query AuditEventQuery) (*AuditEventPage, error)
+	// ListForUser returns every event the user made or was the target of,
+	// oldest first
+	ListForUser(userID uint) ([]model.AuditEvent, error)
+}
+
+type auditRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{
+		db:     db,
+		logger: logger.GetLogger().With(zap.String("component", "audit-repository")),
+	}
+}
+
+func (r *auditRepository) Record(event *model.AuditEvent) error {
+	if err := recordAudit(r.db, event); err != nil {
+		r.logger.Error("Failed to record audit event", zap.String("action", string(event.Action)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// List returns a page of events using keyset pagination on the ID, which
+// grows with the time events are recorded
+func (r *auditRepository) List(query AuditEventQuery) (*AuditEventPage, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultAuditListLimit
+	}
+	if query.Limit > MaxAuditListLimit {
+		query.Limit = MaxAuditListLimit
+	}
+
+	db := r.db.Model(&model.AuditEvent{})
+	if query.ActorID != nil {
+		db = db.Where("actor_id = ?", *query.ActorID)
+	}
+	if query.TargetID != nil {
+		db = db.Where("target_id = ?", *query.TargetID)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if query.From != nil {
+		db = db.Where("occurred_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("occurred_at < ?", *query.To)
+	}
+	if query.Cursor != "" {
+		before, err := decodeAuditCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("id < ?", before)
+	}
+
+	// One extra row tells whether there is a next page
+	var events []model.AuditEvent
+	if err := db.Order("id DESC").Limit(query.Limit + 1).Find(&events).Error; err != nil {
+		r.logger.Error("Failed to list audit events", zap.Error(err))
+		return nil, err
+	}
+
+	page := &AuditEventPage{Events: events, Limit: query.Limit}
+	if len(events) > query.Limit {
+		page.Events = events[:query.Limit]
+		page.HasMore = true
+		page.NextCursor = encodeAuditCursor(page.Events[query.Limit-1].ID)
+	}
+	return page, nil
+}
+
+func (r *auditRepository) ListForUser(userID uint) ([]model.AuditEvent, error) {
+	var events []model.AuditEvent
+	err := r.db.Where("actor_id = ? OR target_id = ?", userID, userID).
+		Order("id").
+		Find(&events).Error
+	if err != nil {
+		r.logger.Error("Failed to list audit events of user", zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// auditCursor is the ID of the last event of a page. It is handed out
+// base64-encoded so clients treat it as opaque.
+type auditCursor struct {
+	ID uint64 `json:"id"`
+}
+
+func encodeAuditCursor(id uint64) string {
+	raw, _ := json.Marshal(auditCursor{ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeAuditCursor(cursor string) (uint64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	var c auditCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == 0 {
+		return 0, ErrInvalidCursor
+	}
+	return c.ID, nil
+}
+
+// recordAudit stores the events with tx, so they are only kept if the change
+// they describe is
+func recordAudit(tx *gorm.DB, events ...*model.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Create(events).Error
+}
+
+// userAuditFields are the user columns whose changes are audited. Redacted
+// columns are recorded as changed without their values: secrets, and personal
+// data, which erasing the user couldn't remove from the append-only log.
+var userAuditFields = map[string]struct {
+	value    func(user *model.User) interface{}
+	redacted bool
+}{
+	"name":                {value: func(u *model.User) interface{} { return u.Name }, redacted: true},
+	"email":               {value: func(u *model.User) interface{} { return u.Email }, redacted: true},
+	"password":            {value: func(u *model.User) interface{} { return u.Password }, redacted: true},
+	"password_changed_at": {value: func(u *model.User) interface{} { return u.PasswordChangedAt }},
+	"email_verified_at":   {value: func(u *model.User) interface{} { return u.EmailVerifiedAt }},
+	"avatar_url":          {value: func(u *model.User) interface{} { return u.AvatarURL }},
+	"erased_at":           {value: func(u *model.User) interface{} { return u.ErasedAt }},
+	"status":              {value: func(u *model.User) interface{} { return string(u.Status) }},
+	"status_reason":       {value: func(u *model.User) interface{} { return u.StatusReason }},
+	"deleted_at": {value: func(u *model.User) interface{} {
+		if !u.DeletedAt.Valid {
+			return nil
+		}
+		return u.DeletedAt.Time
+	}},
+}
+
+// userAuditDiff returns the changes updates make to the audited columns of
+// before. Values computed by the database, such as version increments, are
+// skipped.
+func userAuditDiff(before *model.User, updates map[string]interface{}) model.AuditChanges {
+	changes := make(model.AuditChanges)
+	for column, to := range updates {
+		field, audited := userAuditFields[column]
+		if !audited {
+			continue
+		}
+		if _, computed := to.(clause.Expr); computed {
+			continue
+		}
+
+		from := auditValue(field.value(before))
+		to = auditValue(to)
+		if auditEqual(from, to) {
+			continue
+		}
+		if field.redacted {
+			changes[column] = model.AuditChange{From: model.AuditRedacted, To: model.AuditRedacted}
+			continue
+		}
+		changes[column] = model.AuditChange{From: from, To: to}
+	}
+	return changes
+}
+
+// userAuditSnapshot returns the audited columns of a new user as changes from
+// nothing
+func userAuditSnapshot(user *model.User) model.AuditChanges {
+	changes := userAuditDiff(&model.User{}, map[string]interface{}{
+		"name":              user.Name,
+		"email":             user.Email,
+		"password":          user.Password,
+		"email_verified_at": user.EmailVerifiedAt,
+		"avatar_url":        user.AvatarURL,
+	})
+	for column, change := range changes {
+		change.From = nil
+		changes[column] = change
+	}
+	return changes
+}
+
+// auditValue dereferences pointers and normalizes times to UTC, so values
+// compare and serialize the same however they were set
+func auditValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case *string:
+		if value == nil {
+			return nil
+		}
+		return *value
+	case *time.Time:
+		if value == nil {
+			return nil
+		}
+		return value.UTC()
+	case time.Time:
+		return value.UTC()
+	}
+	return v
+}
+
+func auditEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return a == b
+}
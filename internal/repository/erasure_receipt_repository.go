@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"example/internal/model"
+	"example/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// erasureReceiptBatchSize is how many receipts are loaded per round trip when
+// walking the chain
+const erasureReceiptBatchSize = 500
+
+// ErasureReceiptRepository defines the interface for reading erasure
+// receipts. Receipts are only written by UserRepository.Erase, in the same
+// transaction as the erasure.
+type ErasureReceiptRepository interface {
+	// Each calls fn with every receipt in chain order. An error returned by
+	// fn stops the walk.
+	Each(fn func(receipt model.ErasureReceipt) error) error
+}
+
+type erasureReceiptRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewErasureReceiptRepository(db *gorm.DB) ErasureReceiptRepository {
+	return &erasureReceiptRepository{
+		db:     db,
+		logger: logger.GetLogger().With(zap.String("component", "erasure-receipt-repository")),
+	}
+}
+
+func (r *erasureReceiptRepository) Each(fn func(receipt model.ErasureReceipt) error) error {
+	var receipts []model.ErasureReceipt
+	result := r.db.FindInBatches(&receipts, erasureReceiptBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, receipt := range receipts {
+			if err := fn(receipt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		r.logger.Error("Failed to walk erasure receipts", zap.Error(result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// appendErasureReceipt links the receipt to the last one and stores it. The
+// table is locked until tx ends, so concurrent erasures can't both link to
+// the same receipt.
+func appendErasureReceipt(tx *gorm.DB, receipt *model.ErasureReceipt) error {
+	if err := tx.Exec("LOCK TABLE erasure_receipts IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+		return err
+	}
+
+	var last model.ErasureReceipt
+	if err := tx.Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+		return err
+	}
+
+	receipt.PrevHash = last.Hash
+	receipt.Hash = receipt.ComputeHash()
+	return tx.Create(receipt).Error
+}
@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"example/internal/model"
+	"example/pkg/cache"
+	"example/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLastOwner is returned when removing or demoting the only owner of an
+// organization
+var ErrLastOwner = errors.New("organization must keep at least one owner")
+
+// OrganizationRepository defines the interface for organization and
+// membership data operations
+type OrganizationRepository interface {
+	// Create stores the organization together with its first owner
+	Create(org *model.Organization, owner *model.Membership) error
+	GetByID(id uint) (*model.Organization, error)
+	// GetMembership returns the membership of the user in the organization,
+	// or gorm.ErrRecordNotFound if there is none
+	GetMembership(orgID, userID uint) (*model.Membership, error)
+	// ListMembers returns the memberships of the organization with their users
+	ListMembers(orgID uint) ([]model.Membership, error)
+	// ListForUser returns the memberships of the user with their organizations
+	ListForUser(userID uint) ([]model.Membership, error)
+	// Invite stores an invitation, returning gorm.ErrDuplicatedKey if the
+	// email was already invited
+	Invite(invitation *model.Invitation) error
+	// GetInvitation returns the invitation of the email to the organization,
+	// or gorm.ErrRecordNotFound if there is none
+	GetInvitation(orgID uint, email string) (*model.Invitation, error)
+	// ListInvitations returns the invitations to the organization
+	ListInvitations(orgID uint) ([]model.Invitation, error)
+	// ListInvitationsForEmail returns the invitations of the email with their
+	// organizations
+	ListInvitationsForEmail(email string) ([]model.Invitation, error)
+	// Accept turns the invitation of the email into an active membership of
+	// the user, returning gorm.ErrRecordNotFound if there is no invitation
+	Accept(orgID, userID uint, email string, acceptedAt time.Time) (*model.Membership, error)
+	// DeleteInvitation cancels or declines an invitation, returning
+	// gorm.ErrRecordNotFound if there is none
+	DeleteInvitation(orgID uint, email string) error
+	UpdateRole(orgID, userID uint, role model.OrgRole) error
+	RemoveMember(orgID, userID uint) error
+}
+
+type organizationRepository struct {
+	db           *gorm.DB
+	cacheManager cache.Manager
+	logger       *zap.Logger
+}
+
+func NewOrganizationRepository(db *gorm.DB, cacheManager cache.Manager) OrganizationRepository {
+	return &organizationRepository{
+		db:           db,
+		cacheManager: cacheManager,
+		logger:       logger.GetLogger().With(zap.String("component", "organization-repository")),
+	}
+}
+
+// membershipKey is the cache key of a membership. It is checked on every
+// request made with an organization token, so it is cached.
+func (r *organizationRepository) membershipKey(orgID, userID uint) string {
+	return r.cacheManager.Keys().Key("org", orgID, "member", userID)
+}
+
+func (r *organizationRepository) Create(org *model.Organization, owner *model.Membership) error {
+	r.logger.Info("Creating organization", zap.String("name", org.Name))
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		owner.OrganizationID = org.ID
+		return tx.Create(owner).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to create organization", zap.Error(err))
+		return err
+	}
+
+	r.invalidate(org.ID, owner.UserID)
+	return nil
+}
+
+func (r *organizationRepository) GetByID(id uint) (*model.Organization, error) {
+	var org model.Organization
+	if err := r.db.First(&org, id).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to get organization", zap.Error(err))
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *organizationRepository) GetMembership(orgID, userID uint) (*model.Membership, error) {
+	ctx := context.Background()
+	cacheKey := r.membershipKey(orgID, userID)
+
+	var membership model.Membership
+	if err := r.cacheManager.Get(ctx, cacheKey, &membership); err == nil {
+		return &membership, nil
+	}
+
+	err := r.db.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&membership).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to get membership", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	keys := r.cacheManager.Keys()
+	if err := r.cacheManager.SetDefault(ctx, cacheKey, membership, keys.Tag("user", userID), keys.Tag("org", orgID)); err != nil {
+		r.logger.Error("Failed to cache membership", zap.Error(err))
+		// Don't return the error since we still have the membership
+	}
+
+	return &membership, nil
+}
+
+func (r *organizationRepository) ListMembers(orgID uint) ([]model.Membership, error) {
+	var memberships []model.Membership
+	err := r.db.Preload("User").
+		Where("organization_id = ?", orgID).
+		Order("created_at, user_id").
+		Find(&memberships).Error
+	if err != nil {
+		r.logger.Error("Failed to list members", zap.Error(err))
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (r *organizationRepository) ListForUser(userID uint) ([]model.Membership, error) {
+	var memberships []model.Membership
+	err := r.db.Preload("Organization").
+		Where("user_id = ?", userID).
+		Order("accepted_at NULLS LAST, created_at, organization_id").
+		Find(&memberships).Error
+	if err != nil {
+		r.logger.Error("Failed to list organizations of user", zap.Error(err))
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (r *organizationRepository) Invite(invitation *model.Invitation) error {
+	r.logger.Info("Inviting member", zap.Uint("organization_id", invitation.OrganizationID))
+
+	if err := r.db.Create(invitation).Error; err != nil {
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			r.logger.Error("Failed to invite member", zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *organizationRepository) GetInvitation(orgID uint, email string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.Where("organization_id = ? AND lower(email) = lower(?)", orgID, email).First(&invitation).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to get invitation", zap.Error(err))
+		}
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *organizationRepository) ListInvitations(orgID uint) ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	err := r.db.Where("organization_id = ?", orgID).
+		Order("created_at, email").
+		Find(&invitations).Error
+	if err != nil {
+		r.logger.Error("Failed to list invitations", zap.Error(err))
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *organizationRepository) ListInvitationsForEmail(email string) ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	err := r.db.Preload("Organization").
+		Where("lower(email) = lower(?)", email).
+		Order("created_at, organization_id").
+		Find(&invitations).Error
+	if err != nil {
+		r.logger.Error("Failed to list invitations of email", zap.Error(err))
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *organizationRepository) Accept(orgID, userID uint, email string, acceptedAt time.Time) (*model.Membership, error) {
+	var membership *model.Membership
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var invitation model.Invitation
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("organization_id = ? AND lower(email) = lower(?)", orgID, email).
+			First(&invitation).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("organization_id = ? AND lower(email) = lower(?)", orgID, email).
+			Delete(&model.Invitation{}).Error
+		if err != nil {
+			return err
+		}
+
+		membership = &model.Membership{
+			OrganizationID: orgID,
+			UserID:         userID,
+			Role:           invitation.Role,
+			InvitedBy:      invitation.InvitedBy,
+			AcceptedAt:     &acceptedAt,
+		}
+		return tx.Create(membership).Error
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, gorm.ErrDuplicatedKey) {
+			r.logger.Error("Failed to accept invitation", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	r.invalidate(orgID, userID)
+	return membership, nil
+}
+
+func (r *organizationRepository) DeleteInvitation(orgID uint, email string) error {
+	r.logger.Info("Deleting invitation", zap.Uint("organization_id", orgID))
+
+	result := r.db.Where("organization_id = ? AND lower(email) = lower(?)", orgID, email).Delete(&model.Invitation{})
+	if result.Error != nil {
+		r.logger.Error("Failed to delete invitation", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *organizationRepository) UpdateRole(orgID, userID uint, role model.OrgRole) error {
+	r.logger.Info("Changing member role",
+		zap.Uint("organization_id", orgID),
+		zap.Uint("user_id", userID),
+		zap.String("role", string(role)),
+	)
+
+	err := r.withOwnerCheck(orgID, userID, role == model.OrgRoleOwner, func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&model.Membership{}).
+			Where("organization_id = ? AND user_id = ?", orgID, userID).
+			Update("role", role)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.invalidate(orgID, userID)
+	return nil
+}
+
+func (r *organizationRepository) RemoveMember(orgID, userID uint) error {
+	r.logger.Info("Removing member", zap.Uint("organization_id", orgID), zap.Uint("user_id", userID))
+
+	err := r.withOwnerCheck(orgID, userID, false, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&model.Membership{})
+	})
+	if err != nil {
+		return err
+	}
+
+	r.invalidate(orgID, userID)
+	return nil
+}
+
+// lockActiveOwners selects the accepted owner memberships of users that
+// aren't soft deleted, locking them until tx ends. A deleted owner may be
+// restored, but can't run the organization in the meantime.
+func lockActiveOwners(tx *gorm.DB) *gorm.DB {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "memberships"}}).
+		Joins("JOIN users ON users.id = memberships.user_id AND users.deleted_at IS NULL").
+		Where("memberships.role = ? AND memberships.accepted_at IS NOT NULL", model.OrgRoleOwner)
+}
+
+// withOwnerCheck runs change on the membership unless it would leave the
+// organization without an active owner. The owners are locked first, so two
+// owners demoting each other can't both succeed.
+func (r *organizationRepository) withOwnerCheck(orgID, userID uint, staysOwner bool, change func(tx *gorm.DB) *gorm.DB) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var owners []model.Membership
+		err := lockActiveOwners(tx).
+			Where("memberships.organization_id = ?", orgID).
+			Find(&owners).Error
+		if err != nil {
+			return err
+		}
+
+		if !staysOwner && len(owners) == 1 && owners[0].UserID == userID {
+			return ErrLastOwner
+		}
+
+		result := change(tx)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrLastOwner) && !errors.Is(err, gorm.ErrRecordNotFound) {
+		r.logger.Error("Failed to change membership", zap.Error(err))
+	}
+	return err
+}
+
+// invalidate drops the cached membership and every cached list page and
+// search result, since those are scoped by organization
+func (r *organizationRepository) invalidate(orgID, userID uint) {
+	if err := r.cacheManager.Delete(context.Background(), r.membershipKey(orgID, userID)); err != nil {
+		r.logger.Error("Failed to invalidate membership cache", zap.Error(err))
+	}
+	invalidateUserLists(r.cacheManager, r.logger)
+}
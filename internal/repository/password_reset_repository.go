@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+	"example/internal/model"
+	"example/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrResetTokenInvalid is returned by Consume when no unused, unexpired token
+// has the given hash
+var ErrResetTokenInvalid = errors.New("password reset token is invalid or expired")
+
+// PasswordResetRepository defines the interface for password reset token
+// storage
+type PasswordResetRepository interface {
+	Create(token *model.PasswordResetToken) error
+	Consume(tokenHash string, now time.Time) (*model.PasswordResetToken, error)
+	DeleteForUser(userID uint) error
+	// ListForUser returns the user's tokens, newest first
+	ListForUser(userID uint) ([]model.PasswordResetToken, error)
+}
+
+type passwordResetRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{
+		db:     db,
+		logger: logger.GetLogger().With(zap.String("component", "password-reset-repository")),
+	}
+}
+
+// Create stores a new token, replacing every earlier token of the user so only
+// the most recent reset link works
+func (r *passwordResetRepository) Create(token *model.PasswordResetToken) error {
+	r.logger.Info("Creating password reset token", zap.Uint("user_id", token.UserID))
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", token.UserID).Delete(&model.PasswordResetToken{}).Error; err != nil {
+			r.logger.Error("Failed to delete previous password reset tokens", zap.Error(err))
+			return err
+		}
+		if err := tx.Create(token).Error; err != nil {
+			r.logger.Error("Failed to create password reset token", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+// Consume marks the token with the given hash as used and returns it. The
+// check and the update are a single statement, so concurrent requests can't
+// use the same token twice.
+func (r *passwordResetRepository) Consume(tokenHash string, now time.Time) (*model.PasswordResetToken, error) {
+	var tokens []model.PasswordResetToken
+	result := r.db.Model(&tokens).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		r.logger.Error("Failed to consume password reset token", zap.Error(result.Error))
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || len(tokens) == 0 {
+		return nil, ErrResetTokenInvalid
+	}
+
+	return &tokens[0], nil
+}
+
+// DeleteForUser removes every token of the user, used once the password has
+// been reset
+func (r *passwordResetRepository) DeleteForUser(userID uint) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&model.PasswordResetToken{}).Error; err != nil {
+		r.logger.Error("Failed to delete password reset tokens", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *passwordResetRepository) ListForUser(userID uint) ([]model.PasswordResetToken, error) {
+	var tokens []model.PasswordResetToken
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		r.logger.Error("Failed to list password reset tokens", zap.Error(err))
+		return nil, err
+	}
+	return tokens, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"example/internal/model"
+	"example/pkg/cache"
+	"example/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleRepository defines the interface for the roles granted to users
+type RoleRepository interface {
+	GetRoles(userID uint) ([]model.Role, error)
+	Grant(role *model.UserRole, actor model.AuditActor) error
+	Revoke(userID uint, role model.Role, actor model.AuditActor) error
+}
+
+type roleRepository struct {
+	db           *gorm.DB
+	cacheManager cache.Manager
+	logger       *zap.Logger
+}
+
+func NewRoleRepository(db *gorm.DB, cacheManager cache.Manager) RoleRepository {
+	return &roleRepository{
+		db:           db,
+		cacheManager: cacheManager,
+		logger:       logger.GetLogger().With(zap.String("component", "role-repository")),
+	}
+}
+
+// rolesKey is the cache key of a user's granted roles. It carries the user's
+// tag, so it is dropped along with every other entry of the user.
+func (r *roleRepository) rolesKey(userID uint) string {
+	return r.cacheManager.Keys().Key("user", "roles", userID)
+}
+
+// GetRoles returns the roles explicitly granted to the user. They are checked
+// on every authenticated request, so they are cached.
+func (r *roleRepository) GetRoles(userID uint) ([]model.Role, error) {
+	ctx := context.Background()
+	cacheKey := r.rolesKey(userID)
+
+	var roles []model.Role
+	if err := r.cacheManager.Get(ctx, cacheKey, &roles); err == nil {
+		return roles, nil
+	}
+
+	if err := r.db.Model(&model.UserRole{}).Where("user_id = ?", userID).Order("role").Pluck("role", &roles).Error; err != nil {
+		r.logger.Error("Failed to get user roles", zap.Error(err))
+		return nil, err
+	}
+	if roles == nil {
+		roles = []model.Role{}
+	}
+
+	if err := r.cacheManager.SetDefault(ctx, cacheKey, roles, r.cacheManager.Keys().Tag("user", userID)); err != nil {
+		r.logger.Error("Failed to cache user roles", zap.Error(err))
+		// Don't return the error since we still have the roles
+	}
+
+	return roles, nil
+}
+
+// Grant stores the role and records it in the same transaction. Granting a
+// role the user already has does nothing.
+func (r *roleRepository) Grant(role *model.UserRole, actor model.AuditActor) error {
+	r.logger.Info("Granting role", zap.Uint("user_id", role.UserID), zap.String("role", string(role.Role)))
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(role)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return recordAudit(tx, roleEvent(model.AuditRoleGranted, actor, role.UserID, nil, role.Role))
+	})
+	if err != nil {
+		r.logger.Error("Failed to grant role", zap.Error(err))
+		return err
+	}
+
+	r.invalidate(role.UserID)
+	return nil
+}
+
+// Revoke removes the role and records it in the same transaction, returning
+// gorm.ErrRecordNotFound if the user didn't have it
+func (r *roleRepository) Revoke(userID uint, role model.Role, actor model.AuditActor) error {
+	r.logger.Info("Revoking role", zap.Uint("user_id", userID), zap.String("role", string(role)))
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND role = ?", userID, role).Delete(&model.UserRole{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return recordAudit(tx, roleEvent(model.AuditRoleRevoked, actor, userID, role, nil))
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to revoke role", zap.Error(err))
+		}
+		return err
+	}
+
+	r.invalidate(userID)
+	return nil
+}
+
+// roleEvent is the audit event of a role change, from and to being the role
+// or nil
+func roleEvent(action model.AuditAction, actor model.AuditActor, userID uint, from, to interface{}) *model.AuditEvent {
+	event := model.NewAuditEvent(action, actor, userID)
+	event.Changes = model.AuditChanges{"role": {From: from, To: to}}
+	return event
+}
+
+func (r *roleRepository) invalidate(userID uint) {
+	if err := r.cacheManager.Delete(context.Background(), r.rolesKey(userID)); err != nil {
+		r.logger.Error("Failed to invalidate user roles cache", zap.Error(err))
+	}
+}
@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"example/internal/model"
+	"example/pkg/cache"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Columns users can be sorted by. The ID is always used as a tie-breaker so
+// the ordering is total, which keyset pagination relies on.
+const (
+	UserSortCreatedAt = "created_at"
+	UserSortName      = "name"
+	UserSortEmail     = "email"
+	UserSortID        = "id"
+)
+
+// Page sizes used when the query doesn't set one or asks for too many
+const (
+	DefaultUserListLimit = 20
+	MaxUserListLimit     = 100
+)
+
+// ErrInvalidCursor is returned by List when the cursor can't be decoded or was
+// issued for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// UserListFilter narrows down the users returned by List. Zero values don't
+// filter.
+type UserListFilter struct {
+	// Name and Email match case-insensitive substrings
+	Name          string
+	Email         string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// UserScope restricts which users List and Search return, depending on who is
+// asking. The zero value matches no users, so a forgotten scope never exposes
+// anything.
+type UserScope struct {
+	// All lifts the restriction, for callers allowed to see every user
+	All bool
+	// OrganizationID limits the users to the active members of the
+	// organization
+	OrganizationID uint
+	// UserID limits the users to that user when OrganizationID isn't set
+	UserID uint
+}
+
+// scopeCondition returns the SQL condition matching the users in scope, with
+// its named arguments
+func scopeCondition(scope UserScope) (string, []interface{}) {
+	switch {
+	case scope.All:
+		return "TRUE", nil
+	case scope.OrganizationID != 0:
+		return `EXISTS (
+			SELECT 1 FROM memberships
+			WHERE memberships.user_id = users.id
+				AND memberships.organization_id = @scope_organization_id
+				AND memberships.accepted_at IS NOT NULL)`,
+			[]interface{}{sql.Named("scope_organization_id", scope.OrganizationID)}
+	case scope.UserID != 0:
+		return "users.id = @scope_user_id", []interface{}{sql.Named("scope_user_id", scope.UserID)}
+	default:
+		return "FALSE", nil
+	}
+}
+
+// applyScope restricts db to the users in scope
+func applyScope(db *gorm.DB, scope UserScope) *gorm.DB {
+	if scope.All {
+		return db
+	}
+	condition, args := scopeCondition(scope)
+	return db.Where(condition, args...)
+}
+
+// UserListQuery describes a page of users to fetch
+type UserListQuery struct {
+	Scope  UserScope
+	Filter UserListFilter
+	// SortBy is one of the UserSort* columns, defaulting to created_at
+	SortBy   string
+	SortDesc bool
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor       string
+	Limit        int
+	IncludeTotal bool
+}
+
+// UserPage is a page of users returned by List. Pages are cached under a tag
+// shared by every caller, so the users are loaded without their password
+// hashes.
+type UserPage struct {
+	Users      []model.User `json:"users"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+	HasMore    bool         `json:"has_more"`
+	Limit      int          `json:"limit"`
+	// Total is only set when requested, as it costs an extra count query
+	Total *int64 `json:"total,omitempty"`
+}
+
+// userCursor is the position after the last row of a page. It is handed out
+// base64-encoded so clients treat it as opaque.
+type userCursor struct {
+	SortBy string `json:"s"`
+	Value  string `json:"v"`
+	ID     uint   `json:"id"`
+}
+
+func (c userCursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeUserCursor(cursor string, sortBy string) (*userCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c userCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.SortBy != sortBy {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// sortValue returns the value of the sort column for a user, as stored in a
+// cursor
+func sortValue(user *model.User, sortBy string) string {
+	switch sortBy {
+	case UserSortName:
+		return user.Name
+	case UserSortEmail:
+		return user.Email
+	case UserSortID:
+		return fmt.Sprint(user.ID)
+	default:
+		return user.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// cursorValue converts a cursor value back to the type of the sort column
+func cursorValue(c *userCursor) (interface{}, error) {
+	switch c.SortBy {
+	case UserSortCreatedAt:
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return t, nil
+	default:
+		return c.Value, nil
+	}
+}
+
+func normalizeListQuery(query UserListQuery) UserListQuery {
+	switch query.SortBy {
+	case UserSortCreatedAt, UserSortName, UserSortEmail, UserSortID:
+	default:
+		query.SortBy = UserSortCreatedAt
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultUserListLimit
+	}
+	if query.Limit > MaxUserListLimit {
+		query.Limit = MaxUserListLimit
+	}
+
+	query.Filter.Name = strings.TrimSpace(query.Filter.Name)
+	query.Filter.Email = strings.TrimSpace(query.Filter.Email)
+	return query
+}
+
+// usersListTag groups every cached list page so they can all be dropped when
+// any user changes
+func usersListTag(keys cache.KeyBuilder) string {
+	return keys.Tag("users", "list")
+}
+
+// usersListKey is the cache key of a list page, derived from the whole query
+func (r *userRepository) usersListKey(query UserListQuery) string {
+	raw, _ := json.Marshal(query)
+	sum := sha1.Sum(raw)
+	return r.cacheManager.Keys().Key("users", "list", hex.EncodeToString(sum[:]))
+}
+
+// invalidateUserLists drops every cached list page and search result. Pages
+// are cached per scope, so membership changes drop them too.
+func (r *userRepository) invalidateUserLists() {
+	invalidateUserLists(r.cacheManager, r.logger)
+}
+
+// invalidateUserLists is shared with the organization repository, whose
+// membership changes alter what a scope holds
+func invalidateUserLists(cacheManager cache.Manager, logger *zap.Logger) {
+	keys := cacheManager.Keys()
+	if err := cacheManager.Invalidate(context.Background(), usersListTag(keys), usersSearchTag(keys)); err != nil {
+		logger.Error("Failed to invalidate user list cache", zap.Error(err))
+	}
+}
+
+// List returns a page of users using keyset pagination: instead of an
+// offset, each page continues strictly after the (sort value, id) of the last
+// row of the previous one, so pages stay cheap and stable however deep they
+// go. Only users in the query's scope are returned. Pages are cached briefly
+// and dropped whenever a user or membership changes.
+func (r *userRepository) List(query UserListQuery) (*UserPage, error) {
+	query = normalizeListQuery(query)
+	r.logger.Info("Listing users",
+		zap.String("sort", query.SortBy),
+		zap.Bool("desc", query.SortDesc),
+		zap.Int("limit", query.Limit),
+	)
+
+	ctx := context.Background()
+	cacheKey := r.usersListKey(query)
+
+	var page UserPage
+	if err := r.cacheManager.Get(ctx, cacheKey, &page); err == nil {
+		r.logger.Debug("User list found in cache")
+		return &page, nil
+	}
+
+	db := r.applyListFilter(applyScope(r.db.Model(&model.User{}).Omit("password"), query.Scope), query.Filter)
+
+	if query.IncludeTotal {
+		var total int64
+		if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			r.logger.Error("Failed to count users", zap.Error(err))
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	direction, comparison := "ASC", ">"
+	if query.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeUserCursor(query.Cursor, query.SortBy)
+		if err != nil {
+			return nil, err
+		}
+		value, err := cursorValue(cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if query.SortBy == UserSortID {
+			db = db.Where(fmt.Sprintf("id %s ?", comparison), cursor.ID)
+		} else {
+			db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", query.SortBy, comparison), value, cursor.ID)
+		}
+	}
+
+	if query.SortBy != UserSortID {
+		db = db.Order(fmt.Sprintf("%s %s", query.SortBy, direction))
+	}
+	db = db.Order("id " + direction)
+
+	// Fetch one extra row to find out whether there is another page
+	var users []model.User
+	if err := db.Limit(query.Limit + 1).Find(&users).Error; err != nil {
+		r.logger.Error("Failed to list users", zap.Error(err))
+		return nil, err
+	}
+
+	if len(users) > query.Limit {
+		users = users[:query.Limit]
+		last := &users[len(users)-1]
+		page.HasMore = true
+		page.NextCursor = userCursor{
+			SortBy: query.SortBy,
+			Value:  sortValue(last, query.SortBy),
+			ID:     last.ID,
+		}.encode()
+	}
+	page.Users = users
+	page.Limit = query.Limit
+
+	if err := r.cacheManager.SetDefault(ctx, cacheKey, page, usersListTag(r.cacheManager.Keys())); err != nil {
+		r.logger.Error("Failed to cache user list", zap.Error(err))
+		// Don't return the error since we still have the page
+	}
+
+	return &page, nil
+}
+
+func (r *userRepository) applyListFilter(db *gorm.DB, filter UserListFilter) *gorm.DB {
+	if filter.Name != "" {
+		db = db.Where("name ILIKE ?", "%"+escapeLike(filter.Name)+"%")
+	}
+	if filter.Email != "" {
+		db = db.Where("email ILIKE ?", "%"+escapeLike(filter.Email)+"%")
+	}
+	if filter.CreatedAfter != nil {
+		db = db.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		db = db.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	return db
+}
+
+// escapeLike escapes the LIKE wildcards in a user supplied search term
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUserCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor userCursor
+	}{
+		{name: "created at", cursor: userCursor{SortBy: UserSortCreatedAt, Value: "2024-05-01T10:00:00.123456789Z", ID: 7}},
+		{name: "name", cursor: userCursor{SortBy: UserSortName, Value: "Zoë \"Z\" O'Neil", ID: 42}},
+		{name: "email", cursor: userCursor{SortBy: UserSortEmail, Value: "john+tag@example.com", ID: 1}},
+		{name: "id", cursor: userCursor{SortBy: UserSortID, Value: "99", ID: 99}},
+		{name: "empty value", cursor: userCursor{SortBy: UserSortName, ID: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeUserCursor(tt.cursor.encode(), tt.cursor.SortBy)
+			if err != nil {
+				t.Fatalf("decodeUserCursor() error = %v", err)
+			}
+			if *got != tt.cursor {
+				t.Errorf("decodeUserCursor() = %+v, want %+v", *got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeUserCursorInvalid(t *testing.T) {
+	valid := userCursor{SortBy: UserSortName, Value: "John", ID: 1}.encode()
+
+	tests := []struct {
+		name   string
+		cursor string
+		sortBy string
+	}{
+		{name: "other sort order", cursor: valid, sortBy: UserSortEmail},
+		{name: "not base64", cursor: "not a cursor!", sortBy: UserSortName},
+		{name: "not JSON", cursor: base64.RawURLEncoding.EncodeToString([]byte("name:John")), sortBy: UserSortName},
+		{name: "wrong type", cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"s":"name","id":"one"}`)), sortBy: UserSortName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeUserCursor(tt.cursor, tt.sortBy); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeUserCursor() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestCursorValue(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC)
+
+	tests := []struct {
+		name    string
+		cursor  userCursor
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "created at", cursor: userCursor{SortBy: UserSortCreatedAt, Value: createdAt.Format(time.RFC3339Nano)}, want: createdAt},
+		{name: "invalid created at", cursor: userCursor{SortBy: UserSortCreatedAt, Value: "yesterday"}, wantErr: true},
+		{name: "name", cursor: userCursor{SortBy: UserSortName, Value: "John"}, want: "John"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cursorValue(&tt.cursor)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCursor) {
+					t.Errorf("cursorValue() error = %v, want %v", err, ErrInvalidCursor)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("cursorValue() error = %v", err)
+			}
+			if want, ok := tt.want.(time.Time); ok {
+				if got, ok := got.(time.Time); !ok || !got.Equal(want) {
+					t.Errorf("cursorValue() = %v, want %v", got, want)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("cursorValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeListQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     UserListQuery
+		wantSort  string
+		wantLimit int
+	}{
+		{name: "defaults", query: UserListQuery{}, wantSort: UserSortCreatedAt, wantLimit: DefaultUserListLimit},
+		{name: "unknown sort column", query: UserListQuery{SortBy: "password", Limit: 5}, wantSort: UserSortCreatedAt, wantLimit: 5},
+		{name: "negative limit", query: UserListQuery{SortBy: UserSortName, Limit: -1}, wantSort: UserSortName, wantLimit: DefaultUserListLimit},
+		{name: "limit above max", query: UserListQuery{SortBy: UserSortID, Limit: MaxUserListLimit + 1}, wantSort: UserSortID, wantLimit: MaxUserListLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeListQuery(tt.query)
+			if got.SortBy != tt.wantSort || got.Limit != tt.wantLimit {
+				t.Errorf("normalizeListQuery() sort = %q, limit = %d, want %q, %d", got.SortBy, got.Limit, tt.wantSort, tt.wantLimit)
+			}
+		})
+	}
+}
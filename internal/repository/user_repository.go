@@ -7,18 +7,56 @@ import (
 	"example/pkg/cache"
 	"example/pkg/logger"
 	"fmt"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrVersionConflict is returned by Update when the stored user no longer has
+// the expected version
+var ErrVersionConflict = errors.New("user version conflict")
+
 // UserRepository defines the interface for user repository operations
 type UserRepository interface {
-	Create(user *model.User) error
+	Create(user *model.User, actor model.AuditActor) error
+	CreateBatch(users []*model.User, actor model.AuditActor) error
+	ExistingEmails(emails []string) (map[string]bool, error)
+	Update(user *model.User, expectedVersion uint, actor model.AuditActor) error
+	UpdatePassword(id uint, passwordHash string, changedAt time.Time, actor model.AuditActor) error
+	UpdateEmail(id uint, from, to string, actor model.AuditActor) error
+	MarkEmailVerified(id uint, email string, verifiedAt time.Time, actor model.AuditActor) error
+	UpdateAvatar(id uint, key, url *string, actor model.AuditActor) (*model.User, error)
+	UpdateStatus(id uint, status model.UserStatus, reason *string, changedAt time.Time, actor model.AuditActor) (*model.User, error)
+	RecordLogin(id uint, at time.Time) error
+	Delete(id uint, actor model.AuditActor) error
+	Restore(id uint, actor model.AuditActor) (*model.User, error)
+	Erase(id uint, actor model.AuditActor, erasedAt time.Time, emailHashKey []byte) (*model.User, *model.ErasureReceipt, error)
+	PurgeDeleted(before time.Time) (int64, error)
 	GetByID(id uint) (*model.User, error)
+	GetStatus(id uint) (model.UserStatus, error)
 	GetByEmail(email string) (*model.User, error)
+	GetByIDs(ids []uint) ([]*model.User, error)
+	List(query UserListQuery) (*UserPage, error)
+	Search(scope UserScope, query string, limit int) ([]UserSearchResult, error)
+	WarmCache(recent int) (int, error)
+	EachEmail(fn func(user model.User)) error
+	EachPage(filter UserListFilter, batchSize int, fn func(users []model.User) error) error
 }
 
+// Erased users keep their row, with these in place of their name and email.
+// The .invalid top-level domain is reserved, so the email can never be real.
+const (
+	erasedUserName  = "Erased user"
+	erasedUserEmail = "erased-%d@erased.invalid"
+)
+
+// warmBatchSize is how many users are loaded and cached per round trip when
+// warming the cache
+const warmBatchSize = 500
+
 type userRepository struct {
 	db           *gorm.DB
 	cacheManager cache.Manager
@@ -33,15 +71,612 @@ func NewUserRepository(db *gorm.DB, cacheManager cache.Manager) UserRepository {
 	}
 }
 
-func (r *userRepository) Create(user *model.User) error {
-	return r.db.Create(user).Error
+// userKey is the cache key for a user looked up by ID
+func (r *userRepository) userKey(id uint) string {
+	return r.cacheManager.Keys().Key("user", id)
+}
+
+// userEmailKey is the cache key for a user looked up by email. Emails are
+// case-insensitive, so every case variant shares the entry.
+func (r *userRepository) userEmailKey(email string) string {
+	return r.cacheManager.Keys().Key("user", "email", strings.ToLower(email))
+}
+
+// userStatusKey is the cache key for a user's status. It is looked up on every
+// authenticated request, so it is cached on its own with a short TTL that
+// bounds how long a missed invalidation can keep a suspended user in.
+func (r *userRepository) userStatusKey(id uint) string {
+	return r.cacheManager.Keys().Key("user", "status", id)
+}
+
+// userTag groups every cache entry holding the given user, so they can all be
+// invalidated with a single call when the user changes
+func (r *userRepository) userTag(id uint) string {
+	return r.cacheManager.Keys().Tag("user", id)
+}
+
+// Create stores the user and records its creation in the same transaction
+func (r *userRepository) Create(user *model.User, actor model.AuditActor) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, userCreatedEvent(user, actor))
+	})
+	if err != nil {
+		return err
+	}
+
+	r.invalidateUserLists()
+	return nil
+}
+
+// CreateBatch inserts the users in a single transaction, so either all of
+// them are created or none is
+func (r *userRepository) CreateBatch(users []*model.User, actor model.AuditActor) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	r.logger.Info("Creating users", zap.Int("count", len(users)))
+	if err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(users).Error; err != nil {
+			return err
+		}
+		events := make([]*model.AuditEvent, len(users))
+		for i, user := range users {
+			events[i] = userCreatedEvent(user, actor)
+		}
+		return recordAudit(tx, events...)
+	}); err != nil {
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			r.logger.Error("Failed to create users", zap.Error(err))
+		}
+		return err
+	}
+
+	r.invalidateUserLists()
+	return nil
+}
+
+func userCreatedEvent(user *model.User, actor model.AuditActor) *model.AuditEvent {
+	event := model.NewAuditEvent(model.AuditUserCreated, actor, user.ID)
+	event.Changes = userAuditSnapshot(user)
+	return event
+}
+
+// ExistingEmails returns which of the emails, lowercased, belong to an active
+// user
+func (r *userRepository) ExistingEmails(emails []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+
+	var found []string
+	if err := r.db.Model(&model.User{}).Where("lower(email) IN ?", lowered).Pluck("lower(email)", &found).Error; err != nil {
+		r.logger.Error("Failed to look up existing emails", zap.Error(err))
+		return nil, err
+	}
+	for _, email := range found {
+		existing[email] = true
+	}
+	return existing, nil
+}
+
+// Update saves the user's name, email and email verification if the stored row
+// still has expectedVersion, bumping the version. The user is reloaded
+// afterwards and every cache entry for it is dropped, including the lookups by
+// its previous email, which the tag alone can't be relied on to clear.
+func (r *userRepository) Update(user *model.User, expectedVersion uint, actor model.AuditActor) error {
+	r.logger.Info("Updating user", zap.Uint("id", user.ID), zap.Uint("version", expectedVersion))
+
+	previous, err := r.auditedUpdate(
+		func(tx *gorm.DB) *gorm.DB { return tx.Where("version = ?", expectedVersion) },
+		user.ID,
+		map[string]interface{}{
+			"name":              user.Name,
+			"email":             user.Email,
+			"email_verified_at": user.EmailVerifiedAt,
+			"version":           gorm.Expr("version + 1"),
+		},
+		model.AuditUserUpdated,
+		actor,
+	)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrVersionConflict
+		}
+		r.logger.Error("Failed to update user", zap.Error(err))
+		return err
+	}
+
+	r.invalidateUser(user.ID, previous.Email, user.Email)
+
+	if err := r.db.First(user, user.ID).Error; err != nil {
+		r.logger.Error("Failed to reload user", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// UpdateEmail rewrites the user's email from one spelling to another of the
+// same mailbox, so the verification is kept. Nothing is updated if the email
+// is no longer from, in which case gorm.ErrRecordNotFound is returned.
+func (r *userRepository) UpdateEmail(id uint, from, to string, actor model.AuditActor) error {
+	r.logger.Info("Updating user email", zap.Uint("id", id))
+
+	_, err := r.auditedUpdate(
+		func(tx *gorm.DB) *gorm.DB { return tx.Where("email = ?", from) },
+		id,
+		map[string]interface{}{
+			"email":   to,
+			"version": gorm.Expr("version + 1"),
+		},
+		model.AuditUserUpdated,
+		actor,
+	)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to update user email", zap.Error(err))
+		}
+		return err
+	}
+
+	r.invalidateUser(id, from, to)
+	return nil
+}
+
+// UpdatePassword stores a new password hash and the time it was changed,
+// which invalidates every token issued before
+func (r *userRepository) UpdatePassword(id uint, passwordHash string, changedAt time.Time, actor model.AuditActor) error {
+	r.logger.Info("Updating user password", zap.Uint("id", id))
+
+	_, err := r.auditedUpdate(
+		nil,
+		id,
+		map[string]interface{}{
+			"password":            passwordHash,
+			"password_changed_at": changedAt,
+			"version":             gorm.Expr("version + 1"),
+		},
+		model.AuditUserPasswordChanged,
+		actor,
+	)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to update user password", zap.Error(err))
+		}
+		return err
+	}
+
+	r.invalidateUser(id)
+	return nil
+}
+
+// MarkEmailVerified records that the user confirmed email. Nothing is updated
+// if the user's email changed in the meantime or was already verified, in
+// which case gorm.ErrRecordNotFound is returned.
+func (r *userRepository) MarkEmailVerified(id uint, email string, verifiedAt time.Time, actor model.AuditActor) error {
+	r.logger.Info("Marking user email as verified", zap.Uint("id", id))
+
+	_, err := r.auditedUpdate(
+		func(tx *gorm.DB) *gorm.DB { return tx.Where("email = ? AND email_verified_at IS NULL", email) },
+		id,
+		map[string]interface{}{
+			"email_verified_at": verifiedAt,
+			"version":           gorm.Expr("version + 1"),
+		},
+		model.AuditUserEmailVerified,
+		actor,
+	)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to mark user email as verified", zap.Error(err))
+		}
+		return err
+	}
+
+	r.invalidateUser(id, email)
+	return nil
+}
+
+// RecordLogin stores when the user last logged in. It is bookkeeping rather
+// than a change of the user, so neither the version nor updated_at move and
+// no audit event is written; the login has one of its own.
+func (r *userRepository) RecordLogin(id uint, at time.Time) error {
+	err := r.db.Model(&model.User{}).Where("id = ?", id).UpdateColumn("last_login_at", at).Error
+	if err != nil {
+		r.logger.Error("Failed to record user login", zap.Uint("id", id), zap.Error(err))
+		return err
+	}
+
+	if err := r.cacheManager.Invalidate(context.Background(), r.userTag(id)); err != nil {
+		r.logger.Error("Failed to invalidate user cache tag", zap.Uint("id", id), zap.Error(err))
+	}
+	return nil
+}
+
+// UpdateStatus sets the user's status and the reason for it and returns the
+// user as it was before
+func (r *userRepository) UpdateStatus(id uint, status model.UserStatus, reason *string, changedAt time.Time, actor model.AuditActor) (*model.User, error) {
+	r.logger.Info("Updating user status", zap.Uint("id", id), zap.String("status", string(status)))
+
+	previous, err := r.auditedUpdate(
+		nil,
+		id,
+		map[string]interface{}{
+			"status":            string(status),
+			"status_reason":     reason,
+			"status_changed_at": changedAt,
+			"version":           gorm.Expr("version + 1"),
+		},
+		model.AuditUserStatusChanged,
+		actor,
+	)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to update user status", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	r.invalidateUser(id, previous.Email)
+	return previous, nil
+}
+
+// UpdateAvatar sets or, with nil key and url, clears the user's avatar and
+// returns the user as it was before, so the caller can remove the previous
+// avatar's blobs
+func (r *userRepository) UpdateAvatar(id uint, key, url *string, actor model.AuditActor) (*model.User, error) {
+	r.logger.Info("Updating user avatar", zap.Uint("id", id))
+
+	previous, err := r.auditedUpdate(
+		nil,
+		id,
+		map[string]interface{}{
+			"avatar_key": key,
+			"avatar_url": url,
+			"version":    gorm.Expr("version + 1"),
+		},
+		model.AuditUserAvatarChanged,
+		actor,
+	)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to update user avatar", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	r.invalidateUser(id, previous.Email)
+	return previous, nil
+}
+
+// Delete soft deletes the user and drops it from the cache. Deleting the last
+// active owner of an organization returns ErrLastOwner, as neither the
+// deleted user nor the later purge would leave it one.
+func (r *userRepository) Delete(id uint, actor model.AuditActor) error {
+	r.logger.Info("Deleting user", zap.Uint("id", id))
+
+	var previous *model.User
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		previous, err = auditedUpdateTx(
+			tx,
+			nil,
+			id,
+			map[string]interface{}{"deleted_at": time.Now()},
+			model.AuditUserDeleted,
+			actor,
+		)
+		if err != nil {
+			return err
+		}
+		return checkNotLastOwner(tx, id)
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, ErrLastOwner) {
+			r.logger.Error("Failed to delete user", zap.Error(err))
+		}
+		return err
+	}
+
+	r.invalidateUser(previous.ID, previous.Email)
+	return nil
+}
+
+// Restore undoes a soft delete. It returns gorm.ErrRecordNotFound if there is
+// no deleted user with the ID, or if the user was erased.
+func (r *userRepository) Restore(id uint, actor model.AuditActor) (*model.User, error) {
+	r.logger.Info("Restoring user", zap.Uint("id", id))
+
+	_, err := r.auditedUpdate(
+		func(tx *gorm.DB) *gorm.DB {
+			return tx.Unscoped().Where("deleted_at IS NOT NULL AND erased_at IS NULL")
+		},
+		id,
+		map[string]interface{}{"deleted_at": nil},
+		model.AuditUserRestored,
+		actor,
+	)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to restore user", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	var user model.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+
+	r.invalidateUser(user.ID, user.Email)
+	return &user, nil
+}
+
+// auditedUpdate locks the user with the ID matching scope, nil for active
+// users, applies updates to it and records the change in the same
+// transaction. It returns the user as it was before, or
+// gorm.ErrRecordNotFound if no user matches.
+func (r *userRepository) auditedUpdate(
+	scope func(tx *gorm.DB) *gorm.DB,
+	id uint,
+	updates map[string]interface{},
+	action model.AuditAction,
+	actor model.AuditActor,
+) (*model.User, error) {
+	var previous *model.User
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		previous, err = auditedUpdateTx(tx, scope, id, updates, action, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// auditedUpdateTx is auditedUpdate within a transaction the caller owns
+func auditedUpdateTx(
+	tx *gorm.DB,
+	scope func(tx *gorm.DB) *gorm.DB,
+	id uint,
+	updates map[string]interface{},
+	action model.AuditAction,
+	actor model.AuditActor,
+) (*model.User, error) {
+	locked := tx
+	if scope != nil {
+		locked = scope(tx)
+	}
+	var previous model.User
+	err := locked.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&previous).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// The row is locked, so it can be updated by ID alone
+	if err := tx.Unscoped().Model(&model.User{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	event := model.NewAuditEvent(action, actor, id)
+	event.Changes = userAuditDiff(&previous, updates)
+	if err := recordAudit(tx, event); err != nil {
+		return nil, err
+	}
+	return &previous, nil
+}
+
+// Erase anonymizes the user, soft deleted or not, and records the erasure
+// receipt and audit event in the same transaction. The name, email, password
+// and avatar are overwritten, the user is soft deleted if it wasn't already,
+// and its roles, memberships, password reset tokens and the invitations sent
+// to its email are removed. The audit event only lists the erased fields, not
+// their values. Erase returns the user as it was before, so the caller can
+// remove the avatar's blobs, or gorm.ErrRecordNotFound if there is no such
+// user or it was already erased. Erasing the last owner of an organization
+// returns ErrLastOwner. The receipt's email hash is keyed with emailHashKey.
+func (r *userRepository) Erase(id uint, actor model.AuditActor, erasedAt time.Time, emailHashKey []byte) (*model.User, *model.ErasureReceipt, error) {
+	r.logger.Info("Erasing user", zap.Uint("id", id))
+
+	erasedAt = erasedAt.UTC().Truncate(time.Microsecond)
+	var previous model.User
+	var receipt *model.ErasureReceipt
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("erased_at IS NULL").
+			First(&previous, id).Error
+		if err != nil {
+			return err
+		}
+
+		if err := checkNotLastOwner(tx, id); err != nil {
+			return err
+		}
+
+		for _, related := range []interface{}{&model.Membership{}, &model.UserRole{}, &model.PasswordResetToken{}} {
+			if err := tx.Where("user_id = ?", id).Delete(related).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("lower(email) = lower(?)", previous.Email).Delete(&model.Invitation{}).Error; err != nil {
+			return err
+		}
+
+		deletedAt := erasedAt
+		if previous.DeletedAt.Valid {
+			deletedAt = previous.DeletedAt.Time
+		}
+		updates := map[string]interface{}{
+			"name":                erasedUserName,
+			"email":               fmt.Sprintf(erasedUserEmail, id),
+			"password":            "",
+			"password_changed_at": erasedAt,
+			"email_verified_at":   nil,
+			"avatar_key":          nil,
+			"avatar_url":          nil,
+			"last_login_at":       nil,
+			"erased_at":           erasedAt,
+			"deleted_at":          deletedAt,
+			"version":             gorm.Expr("version + 1"),
+		}
+		if err := tx.Unscoped().Model(&model.User{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		event := model.NewAuditEvent(model.AuditUserErased, actor, id)
+		event.Changes = userAuditDiff(&previous, updates)
+		for field := range event.Changes {
+			event.Changes[field] = model.AuditChange{From: model.AuditRedacted, To: model.AuditRedacted}
+		}
+		if err := recordAudit(tx, event); err != nil {
+			return err
+		}
+
+		receipt = &model.ErasureReceipt{
+			UserID:      id,
+			EmailHash:   model.ErasureEmailHash(emailHashKey, previous.Email),
+			RequestedBy: actor.UserID,
+			ErasedAt:    erasedAt,
+		}
+		return appendErasureReceipt(tx, receipt)
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, ErrLastOwner) {
+			r.logger.Error("Failed to erase user", zap.Error(err))
+		}
+		return nil, nil, err
+	}
+
+	// The tag also covers the user's cached roles and memberships
+	r.invalidateUser(id, previous.Email)
+	return &previous, receipt, nil
+}
+
+// checkNotLastOwner returns ErrLastOwner if the user is the only active owner
+// of an organization. The owners are locked until tx ends, like in
+// organizationRepository.withOwnerCheck. Owners that are soft deleted don't
+// count, and neither does the user, so the check holds whether the user was
+// already deleted in tx or not.
+func checkNotLastOwner(tx *gorm.DB, userID uint) error {
+	var owned []uint
+	err := tx.Model(&model.Membership{}).
+		Where("user_id = ? AND role = ? AND accepted_at IS NOT NULL", userID, model.OrgRoleOwner).
+		Pluck("organization_id", &owned).Error
+	if err != nil || len(owned) == 0 {
+		return err
+	}
+
+	var ownerships []model.Membership
+	err = lockActiveOwners(tx).
+		Where("memberships.organization_id IN ?", owned).
+		Find(&ownerships).Error
+	if err != nil {
+		return err
+	}
+
+	others := make(map[uint]int, len(owned))
+	for _, owner := range ownerships {
+		if owner.UserID != userID {
+			others[owner.OrganizationID]++
+		}
+	}
+	for _, orgID := range owned {
+		if others[orgID] == 0 {
+			return ErrLastOwner
+		}
+	}
+	return nil
+}
+
+// PurgeDeleted permanently removes users soft deleted before the given time
+// and returns how many were removed
+func (r *userRepository) PurgeDeleted(before time.Time) (int64, error) {
+	r.logger.Info("Purging deleted users", zap.Time("before", before))
+
+	result := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&model.User{})
+	if result.Error != nil {
+		r.logger.Error("Failed to purge deleted users", zap.Error(result.Error))
+		return 0, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		r.invalidateUserLists()
+	}
+
+	return result.RowsAffected, nil
+}
+
+// invalidateUser drops every cache entry for the user: the id key, the given
+// email keys, anything else tagged with the user, all list pages and search
+// results
+func (r *userRepository) invalidateUser(id uint, emails ...string) {
+	ctx := context.Background()
+
+	keys := []string{r.userKey(id), r.userStatusKey(id)}
+	for _, email := range emails {
+		keys = append(keys, r.userEmailKey(email))
+	}
+	for _, key := range keys {
+		if err := r.cacheManager.Delete(ctx, key); err != nil {
+			r.logger.Error("Failed to evict user from cache", zap.String("key", key), zap.Error(err))
+		}
+	}
+
+	if err := r.cacheManager.Invalidate(ctx, r.userTag(id)); err != nil {
+		r.logger.Error("Failed to invalidate user cache tag", zap.Uint("id", id), zap.Error(err))
+	}
+
+	r.invalidateUserLists()
+}
+
+// GetStatus returns the status of the active user with the ID, through a
+// cache entry of its own, or gorm.ErrRecordNotFound if there is no such user
+func (r *userRepository) GetStatus(id uint) (model.UserStatus, error) {
+	var status model.UserStatus
+	cacheKey := r.userStatusKey(id)
+	if err := r.cacheManager.Get(context.Background(), cacheKey, &status); err == nil {
+		return status, nil
+	}
+
+	var user model.User
+	if err := r.db.Select("id", "status").First(&user, id).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to get user status from database", zap.Error(err))
+		}
+		return "", err
+	}
+	if user.Status == "" {
+		user.Status = model.UserStatusActive
+	}
+
+	if err := r.cacheManager.SetDefault(context.Background(), cacheKey, user.Status, r.userTag(id)); err != nil {
+		r.logger.Error("Failed to cache user status", zap.Error(err))
+	}
+
+	return user.Status, nil
 }
 
 func (r *userRepository) GetByID(id uint) (*model.User, error) {
 	r.logger.Info("Getting user by ID", zap.Uint("id", id))
 
 	var user model.User
-	cacheKey := fmt.Sprintf("user:%d", id)
+	cacheKey := r.userKey(id)
 
 	// Try to get from cache first
 	r.logger.Debug("Checking cache", zap.String("key", cacheKey))
@@ -60,7 +695,7 @@ func (r *userRepository) GetByID(id uint) (*model.User, error) {
 
 	// Store in cache
 	r.logger.Debug("Storing user in cache", zap.Uint("id", id))
-	if err := r.cacheManager.SetDefault(context.Background(), cacheKey, user); err != nil {
+	if err := r.cacheManager.SetDefault(context.Background(), cacheKey, user, r.userTag(user.ID)); err != nil {
 		r.logger.Error("Failed to cache user", zap.Error(err))
 		// Don't return the error since we still have the user
 	}
@@ -70,7 +705,7 @@ func (r *userRepository) GetByID(id uint) (*model.User, error) {
 
 func (r *userRepository) GetByEmail(email string) (*model.User, error) {
 	var user model.User
-	cacheKey := fmt.Sprintf("user:email:%s", email)
+	cacheKey := r.userEmailKey(email)
 
 	// Try to get from cache first
 	err := r.cacheManager.Get(context.Background(), cacheKey, &user)
@@ -78,9 +713,10 @@ func (r *userRepository) GetByEmail(email string) (*model.User, error) {
 		return &user, nil
 	}
 
-	// If not in cache, get from database
-	user = model.User{Email: email}
-	result := r.db.Where(user).First(&user)
+	// If not in cache, get from database. lower(email) is what the unique
+	// index is on, so the lookup can use it.
+	user = model.User{}
+	result := r.db.Where("lower(email) = lower(?)", email).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -89,10 +725,189 @@ func (r *userRepository) GetByEmail(email string) (*model.User, error) {
 	}
 
 	// Cache the user with default expiration
-	if err := r.cacheManager.SetDefault(context.Background(), cacheKey, user); err != nil {
+	if err := r.cacheManager.SetDefault(context.Background(), cacheKey, user, r.userTag(user.ID)); err != nil {
 		r.logger.Error("Failed to cache user", zap.Error(err))
 		// Don't return the error since we still have the user
 	}
 
 	return &user, nil
 }
+
+// GetByIDs returns the users with the given IDs in the order requested,
+// skipping IDs that don't exist. Cached users are fetched in one pipeline and
+// the misses are loaded with a single query and written back the same way.
+func (r *userRepository) GetByIDs(ids []uint) ([]*model.User, error) {
+	r.logger.Info("Getting users by IDs", zap.Int("count", len(ids)))
+
+	if len(ids) == 0 {
+		return []*model.User{}, nil
+	}
+
+	ctx := context.Background()
+	found := make(map[uint]*model.User, len(ids))
+
+	keys := make([]string, 0, len(ids))
+	seen := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		keys = append(keys, r.userKey(id))
+	}
+
+	// Try to get from cache first
+	cached, err := r.cacheManager.MGet(ctx, keys)
+	if err != nil {
+		r.logger.Error("Failed to get users from cache", zap.Error(err))
+		// Fall through to the database for everything
+	}
+
+	var missing []uint
+	for id := range seen {
+		var user model.User
+		ok, err := cached.Decode(r.userKey(id), &user)
+		if err != nil {
+			r.logger.Error("Failed to decode cached user", zap.Uint("id", id), zap.Error(err))
+		}
+		if !ok || err != nil {
+			missing = append(missing, id)
+			continue
+		}
+		found[id] = &user
+	}
+
+	// Load everything the cache didn't have in one query
+	if len(missing) > 0 {
+		r.logger.Debug("Users not found in cache, querying database", zap.Int("count", len(missing)))
+
+		var users []model.User
+		if err := r.db.Where("id IN ?", missing).Find(&users).Error; err != nil {
+			r.logger.Error("Failed to get users from database", zap.Error(err))
+			return nil, err
+		}
+
+		entries := make([]cache.Entry, 0, len(users))
+		for i := range users {
+			user := &users[i]
+			found[user.ID] = user
+			entries = append(entries, cache.Entry{
+				Key:   r.userKey(user.ID),
+				Value: user,
+				Tags:  []string{r.userTag(user.ID)},
+			})
+		}
+
+		// Store in cache
+		if err := r.cacheManager.MSet(ctx, entries); err != nil {
+			r.logger.Error("Failed to cache users", zap.Error(err))
+			// Don't return the error since we still have the users
+		}
+	}
+
+	result := make([]*model.User, 0, len(found))
+	for _, id := range ids {
+		if user, ok := found[id]; ok {
+			result = append(result, user)
+			delete(found, id)
+		}
+	}
+
+	return result, nil
+}
+
+// WarmCache writes the id and email cache entries for users ahead of traffic.
+// With recent > 0 only the users who logged in most recently are warmed,
+// otherwise every user is. It returns the number of users cached.
+func (r *userRepository) WarmCache(recent int) (int, error) {
+	r.logger.Info("Warming user cache", zap.Int("recent", recent))
+
+	if recent > 0 {
+		var users []model.User
+		if err := r.db.Order("last_login_at DESC NULLS LAST").Order("id DESC").Limit(recent).Find(&users).Error; err != nil {
+			r.logger.Error("Failed to get recent users from database", zap.Error(err))
+			return 0, err
+		}
+
+		for start := 0; start < len(users); start += warmBatchSize {
+			end := min(start+warmBatchSize, len(users))
+			if err := r.cacheUsers(users[start:end]); err != nil {
+				return start, err
+			}
+		}
+
+		return len(users), nil
+	}
+
+	warmed := 0
+	var users []model.User
+	result := r.db.FindInBatches(&users, warmBatchSize, func(tx *gorm.DB, batch int) error {
+		if err := r.cacheUsers(users); err != nil {
+			return err
+		}
+		warmed += len(users)
+		return nil
+	})
+	if result.Error != nil {
+		r.logger.Error("Failed to warm user cache", zap.Error(result.Error))
+		return warmed, result.Error
+	}
+
+	return warmed, nil
+}
+
+// EachEmail calls fn with the ID and email of every active user, loading them
+// in batches
+func (r *userRepository) EachEmail(fn func(user model.User)) error {
+	var users []model.User
+	result := r.db.Select("id", "email", "created_at").FindInBatches(&users, warmBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, user := range users {
+			fn(user)
+		}
+		return nil
+	})
+	if result.Error != nil {
+		r.logger.Error("Failed to scan user emails", zap.Error(result.Error))
+		return result.Error
+	}
+
+	return nil
+}
+
+// EachPage calls fn with consecutive pages of the active users matching the
+// filter, in ID order. Pages continue after the last ID of the previous one
+// and bypass the cache, so walking every user keeps memory flat. Password
+// hashes are never loaded. An error returned by fn stops the walk.
+func (r *userRepository) EachPage(filter UserListFilter, batchSize int, fn func(users []model.User) error) error {
+	var users []model.User
+	result := r.applyListFilter(r.db.Omit("password"), filter).FindInBatches(&users, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(users)
+	})
+	if result.Error != nil {
+		r.logger.Error("Failed to walk users", zap.Error(result.Error))
+		return result.Error
+	}
+
+	return nil
+}
+
+// cacheUsers writes both the id and the email entry of every user in one
+// pipeline
+func (r *userRepository) cacheUsers(users []model.User) error {
+	entries := make([]cache.Entry, 0, len(users)*2)
+	for i := range users {
+		user := &users[i]
+		tags := []string{r.userTag(user.ID)}
+		entries = append(entries,
+			cache.Entry{Key: r.userKey(user.ID), Value: user, Tags: tags},
+			cache.Entry{Key: r.userEmailKey(user.Email), Value: user, Tags: tags},
+		)
+	}
+
+	if err := r.cacheManager.MSet(context.Background(), entries); err != nil {
+		r.logger.Error("Failed to cache users", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
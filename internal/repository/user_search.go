@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"example/internal/model"
+	"example/pkg/cache"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"go.uber.org/zap"
+)
+
+// Result counts used when the search doesn't set one or asks for too many
+const (
+	DefaultUserSearchLimit = 20
+	MaxUserSearchLimit     = 50
+)
+
+// UserSearchResult is a user matching a search, with its relevance and the
+// matched parts of its name and email wrapped in <mark> tags. Results are
+// cached under a tag shared by every caller, so the user comes without its
+// password hash.
+type UserSearchResult struct {
+	User           model.User `json:"user"`
+	Rank           float64    `json:"rank"`
+	NameHighlight  string     `json:"name_highlight"`
+	EmailHighlight string     `json:"email_highlight"`
+}
+
+// userSearchRow is a users row with the rank computed by the search query
+type userSearchRow struct {
+	model.User
+	Rank float64
+}
+
+// NormalizeSearchQuery lowercases a search query and collapses whitespace, so
+// equivalent queries share a cache entry
+func NormalizeSearchQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// searchTerms splits a normalized query into the words used for full-text
+// matching and highlighting, dropping anything that isn't a letter or digit
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// prefixTSQuery builds a tsquery matching words starting with every term,
+// e.g. "jo smi" becomes "jo:* & smi:*"
+func prefixTSQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+// highlight HTML-escapes text and wraps case-insensitive occurrences of the
+// terms in <mark> tags
+func highlight(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		// Lowercasing changed byte offsets, so matches can't be mapped back
+		return html.EscapeString(text)
+	}
+
+	marked := make([]bool, len(text))
+	for _, term := range terms {
+		for start := 0; ; {
+			i := strings.Index(lower[start:], term)
+			if i < 0 {
+				break
+			}
+			for j := start + i; j < start+i+len(term); j++ {
+				marked[j] = true
+			}
+			start += i + len(term)
+		}
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(text); {
+		j := i
+		for j < len(text) && marked[j] == marked[i] {
+			j++
+		}
+		segment := html.EscapeString(text[i:j])
+		if marked[i] {
+			sb.WriteString("<mark>" + segment + "</mark>")
+		} else {
+			sb.WriteString(segment)
+		}
+		i = j
+	}
+	return sb.String()
+}
+
+// usersSearchTag groups every cached search result so they can be dropped
+// together with the list pages whenever a user changes
+func usersSearchTag(keys cache.KeyBuilder) string {
+	return keys.Tag("users", "search")
+}
+
+func (r *userRepository) usersSearchKey(scope UserScope, query string, limit int) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%t:%d:%d:%d:%s", scope.All, scope.OrganizationID, scope.UserID, limit, query)))
+	return r.cacheManager.Keys().Key("users", "search", hex.EncodeToString(sum[:]))
+}
+
+// Search finds users by partial name or email. Prefix full-text matches and
+// trigram word similarity are combined into a single rank, so both "jo smi"
+// and a misspelled "jonh" find John Smith. Results are cached briefly under
+// the scope and normalized query.
+func (r *userRepository) Search(scope UserScope, query string, limit int) ([]UserSearchResult, error) {
+	query = NormalizeSearchQuery(query)
+	if limit <= 0 {
+		limit = DefaultUserSearchLimit
+	}
+	if limit > MaxUserSearchLimit {
+		limit = MaxUserSearchLimit
+	}
+
+	r.logger.Info("Searching users", zap.String("query", query), zap.Int("limit", limit))
+
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []UserSearchResult{}, nil
+	}
+
+	ctx := context.Background()
+	cacheKey := r.usersSearchKey(scope, query, limit)
+
+	var results []UserSearchResult
+	if err := r.cacheManager.Get(ctx, cacheKey, &results); err == nil {
+		r.logger.Debug("Search results found in cache")
+		return results, nil
+	}
+
+	condition, scopeArgs := scopeCondition(scope)
+	args := append([]interface{}{
+		sql.Named("term", query),
+		sql.Named("tsquery", prefixTSQuery(terms)),
+		sql.Named("limit", limit),
+	}, scopeArgs...)
+
+	var rows []userSearchRow
+	err := r.db.Raw(fmt.Sprintf(`
+		SELECT users.*,
+			ts_rank(users.search_vector, query) +
+				greatest(word_similarity(@term, users.name), word_similarity(@term, users.email)) AS rank
+		FROM users, to_tsquery('simple', @tsquery) AS query
+		WHERE users.deleted_at IS NULL
+			AND (users.search_vector @@ query OR @term <%% users.name OR @term <%% users.email)
+			AND %s
+		ORDER BY rank DESC, users.id
+		LIMIT @limit`, condition),
+		args...,
+	).Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("Failed to search users", zap.Error(err))
+		return nil, err
+	}
+
+	results = make([]UserSearchResult, len(rows))
+	for i, row := range rows {
+		row.User.Password = ""
+		results[i] = UserSearchResult{
+			User:           row.User,
+			Rank:           row.Rank,
+			NameHighlight:  highlight(row.Name, terms),
+			EmailHighlight: highlight(row.Email, terms),
+		}
+	}
+
+	if err := r.cacheManager.SetDefault(ctx, cacheKey, results, usersSearchTag(r.cacheManager.Keys())); err != nil {
+		r.logger.Error("Failed to cache search results", zap.Error(err))
+		// Don't return the error since we still have the results
+	}
+
+	return results, nil
+}
@@ -2,13 +2,33 @@ package router
 
 import (
 	"example/internal/http/handler"
+	"example/internal/model"
+	"example/internal/policy"
+	"example/internal/service"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRouter(userHandler handler.UserHandler, authHandler handler.AuthHandler) *gin.Engine {
+func SetupRouter(
+	userHandler handler.UserHandler,
+	authHandler handler.AuthHandler,
+	healthHandler handler.HealthHandler,
+	cacheHandler handler.CacheHandler,
+	passwordResetHandler handler.PasswordResetHandler,
+	emailVerificationHandler handler.EmailVerificationHandler,
+	avatarHandler handler.AvatarHandler,
+	roleHandler handler.RoleHandler,
+	organizationHandler handler.OrganizationHandler,
+	userImportHandler handler.UserImportHandler,
+	userExportHandler handler.UserExportHandler,
+	privacyHandler handler.PrivacyHandler,
+	auditHandler handler.AuditHandler,
+	loginLockoutHandler handler.LoginLockoutHandler,
+	policies *policy.Routes,
+	passwordResetLimiter service.RateLimiter,
+) *gin.Engine {
 	r := gin.Default()
 	if err := r.SetTrustedProxies(nil); err != nil {
 		panic("Failed to set trusted proxies: " + err.Error())
@@ -20,10 +40,16 @@ func SetupRouter(userHandler handler.UserHandler, authHandler handler.AuthHandle
 	// Public routes
 	api := r.Group("/api")
 	{
+		api.GET("/health", healthHandler.Check)
+
 		auth := api.Group("/auth")
 		{
 			auth.POST("/login", authHandler.Middleware().LoginHandler)
-			auth.GET("/refresh", authHandler.Middleware().RefreshHandler)
+			auth.GET("/refresh", authHandler.Refresh)
+			auth.POST("/password/forgot", handler.RateLimitByIP(passwordResetLimiter), passwordResetHandler.Forgot)
+			auth.POST("/password/reset", passwordResetHandler.Reset)
+			auth.POST("/email/confirm", emailVerificationHandler.Confirm)
+			auth.POST("/email/resend", emailVerificationHandler.Resend)
 		}
 
 		// Public user routes
@@ -36,8 +62,69 @@ func SetupRouter(userHandler handler.UserHandler, authHandler handler.AuthHandle
 		protected := users.Group("")
 		protected.Use(authHandler.Middleware().MiddlewareFunc())
 		{
-			protected.GET("/:id", userHandler.Get)
+			protected.GET("", userHandler.List)
+			protected.GET("/search", userHandler.Search)
+			protected.GET("/:id", handler.AuthorizeUser(policies.ReadUser), userHandler.Get)
 			protected.GET("/me", userHandler.GetMe)
+			protected.PATCH("/:id", userHandler.Update)
+			protected.PATCH("/me", userHandler.UpdateMe)
+			protected.POST("/me/password", userHandler.ChangePassword)
+			protected.GET("/me/data", privacyHandler.ExportMe)
+			protected.POST("/me/erase", privacyHandler.EraseMe)
+			protected.PUT("/me/avatar", avatarHandler.Upload)
+			protected.DELETE("/me/avatar", avatarHandler.Delete)
+			protected.DELETE("/:id", userHandler.Delete)
+		}
+
+		// Organization routes; what members may do depends on their role in
+		// the organization
+		organizations := api.Group("/organizations")
+		organizations.Use(authHandler.Middleware().MiddlewareFunc())
+		{
+			organizations.POST("", organizationHandler.Create)
+			organizations.GET("", organizationHandler.List)
+			organizations.GET("/:id", organizationHandler.Get)
+			organizations.POST("/:id/accept", organizationHandler.Accept)
+			organizations.POST("/:id/decline", organizationHandler.Decline)
+			organizations.GET("/:id/members", organizationHandler.ListMembers)
+			organizations.POST("/:id/members", organizationHandler.Invite)
+			organizations.GET("/:id/invitations", organizationHandler.ListInvitations)
+			organizations.DELETE("/:id/invitations", organizationHandler.CancelInvitation)
+			organizations.PATCH("/:id/members/:user_id", organizationHandler.UpdateMember)
+			organizations.DELETE("/:id/members/:user_id", organizationHandler.RemoveMember)
+		}
+
+		// Admin routes, open to admins and to support staff for what their
+		// role permits
+		admin := api.Group("/admin")
+		admin.Use(authHandler.Middleware().MiddlewareFunc(), handler.RequireRole(model.RoleAdmin, model.RoleSupport))
+		{
+			cacheRead := handler.RequirePermission(model.PermissionCacheRead)
+			cacheWrite := handler.RequirePermission(model.PermissionCacheWrite)
+			adminCache := admin.Group("/cache")
+			adminCache.POST("/warmup", cacheWrite, cacheHandler.Warmup)
+			adminCache.GET("/keys/:key", cacheRead, cacheHandler.Get)
+			adminCache.GET("/keys/:key/ttl", cacheRead, cacheHandler.GetTTL)
+			adminCache.DELETE("/keys/:key", cacheWrite, cacheHandler.Delete)
+
+			rolesRead := handler.RequirePermission(model.PermissionRolesRead)
+			rolesManage := handler.RequirePermission(model.PermissionRolesManage)
+			usersSuspend := handler.RequirePermission(model.PermissionUsersSuspend)
+			adminUsers := admin.Group("/users")
+			adminUsers.POST("/import", handler.RequirePermission(model.PermissionUsersImport), userImportHandler.Import)
+			adminUsers.GET("/export", handler.RequirePermission(model.PermissionUsersExport), userExportHandler.Export)
+			adminUsers.POST("/:id/restore", handler.RequirePermission(model.PermissionUsersRestore), userHandler.Restore)
+			adminUsers.GET("/:id/data", handler.RequirePermission(model.PermissionUsersExport), privacyHandler.Export)
+			adminUsers.POST("/:id/erase", handler.RequirePermission(model.PermissionUsersErase), privacyHandler.Erase)
+			adminUsers.POST("/:id/unlock", handler.RequirePermission(model.PermissionUsersUnlock), loginLockoutHandler.Unlock)
+			adminUsers.POST("/:id/suspend", usersSuspend, userHandler.Suspend)
+			adminUsers.POST("/:id/disable", usersSuspend, userHandler.Disable)
+			adminUsers.POST("/:id/reactivate", usersSuspend, userHandler.Reactivate)
+			adminUsers.GET("/:id/roles", rolesRead, roleHandler.List)
+			adminUsers.POST("/:id/roles", rolesManage, roleHandler.Grant)
+			adminUsers.DELETE("/:id/roles/:role", rolesManage, roleHandler.Revoke)
+
+			admin.GET("/audit-events", handler.RequirePermission(model.PermissionAuditRead), auditHandler.List)
 		}
 	}
 
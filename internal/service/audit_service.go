@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// AuditService defines the interface for the audit log
+type AuditService interface {
+	// Record stores an event that doesn't come with a change, such as a
+	// login. Failures are logged rather than returned, so they never turn a
+	// login away.
+	Record(event *model.AuditEvent)
+	List(query repository.AuditEventQuery) (*repository.AuditEventPage, error)
+}
+
+type auditService struct {
+	repo   repository.AuditRepository
+	logger *zap.Logger
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{
+		repo:   repo,
+		logger: logger.GetLogger().With(zap.String("component", "audit-service")),
+	}
+}
+
+func (s *auditService) Record(event *model.AuditEvent) {
+	if err := s.repo.Record(event); err != nil {
+		s.logger.Error("Audit event lost",
+			zap.String("action", string(event.Action)),
+			zap.Uintp("actor_id", event.ActorID),
+			zap.Uintp("target_id", event.TargetID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *auditService) List(query repository.AuditEventQuery) (*repository.AuditEventPage, error) {
+	page, err := s.repo.List(query)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, ErrInvalidCursor
+		}
+		return nil, translateError(err)
+	}
+	return page, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+
+	// Decoders for the accepted avatar formats
+	_ "image/gif"
+	_ "image/jpeg"
+
+	_ "golang.org/x/image/webp"
+	"golang.org/x/image/draw"
+)
+
+// avatarContentTypes are the sniffed content types accepted as avatars
+var avatarContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// maxAvatarPixels bounds the decoded size of an upload, so a small file
+// can't expand into gigabytes of pixels
+const maxAvatarPixels = 4096 * 4096
+
+// sniffAvatar checks the upload really is one of the accepted image formats,
+// whatever the client claimed, and that its dimensions are reasonable
+func sniffAvatar(data []byte) error {
+	if !avatarContentTypes[http.DetectContentType(data)] {
+		return &ValidationError{Message: "avatar must be a PNG, JPEG, GIF or WebP image"}
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return &ValidationError{Message: "avatar image is corrupt", Err: err}
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxAvatarPixels {
+		return &ValidationError{Message: "avatar image dimensions are too large"}
+	}
+
+	return nil
+}
+
+// avatarThumbnails crops the image to a centered square and scales it to
+// each size, returning the PNG encoded thumbnails by size
+func avatarThumbnails(data []byte, sizes []int) (map[int][]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &ValidationError{Message: "avatar image is corrupt", Err: err}
+	}
+
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	square := image.Rect(x, y, x+side, y+side)
+
+	thumbnails := make(map[int][]byte, len(sizes))
+	for _, size := range sizes {
+		dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, square, draw.Src, nil)
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, err
+		}
+		thumbnails[size] = buf.Bytes()
+	}
+
+	return thumbnails, nil
+}
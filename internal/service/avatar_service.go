@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"example/internal/config"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/logger"
+	"example/pkg/storage"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const defaultAvatarMaxBytes = 5 << 20
+
+// AvatarService defines the interface for user avatar operations
+type AvatarService interface {
+	Upload(userID uint, r io.Reader, actor model.AuditActor) (*model.User, error)
+	Delete(userID uint, actor model.AuditActor) (*model.User, error)
+	MaxBytes() int64
+}
+
+type avatarService struct {
+	userRepo repository.UserRepository
+	store    storage.BlobStore
+	maxBytes int64
+	logger   *zap.Logger
+}
+
+func NewAvatarService(userRepo repository.UserRepository, store storage.BlobStore, cfg *config.UsersConfig) AvatarService {
+	maxBytes := cfg.AvatarMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultAvatarMaxBytes
+	}
+
+	return &avatarService{
+		userRepo: userRepo,
+		store:    store,
+		maxBytes: maxBytes,
+		logger:   logger.GetLogger().With(zap.String("component", "avatar-service")),
+	}
+}
+
+// MaxBytes is the largest upload accepted
+func (s *avatarService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// Upload stores the image as the user's avatar, resized to every
+// model.AvatarSizes. Each upload gets a new random prefix so cached copies of
+// the previous avatar are never served in its place; the previous avatar is
+// removed once the new one is saved.
+func (s *avatarService) Upload(userID uint, r io.Reader, actor model.AuditActor) (*model.User, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxBytes {
+		return nil, &ValidationError{Message: fmt.Sprintf("avatar must not be larger than %d bytes", s.maxBytes)}
+	}
+
+	if err := sniffAvatar(data); err != nil {
+		return nil, err
+	}
+	thumbnails, err := avatarThumbnails(data, model.AvatarSizes)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	prefix := fmt.Sprintf("avatars/%d/%s", userID, hex.EncodeToString(suffix))
+
+	ctx := context.Background()
+	for size, thumbnail := range thumbnails {
+		if err := s.store.Put(ctx, prefix+"/"+model.AvatarFile(size), thumbnail, "image/png"); err != nil {
+			s.logger.Error("Failed to store avatar", zap.Uint("user_id", userID), zap.Error(err))
+			s.deleteBlobs(prefix)
+			return nil, &UnavailableError{Message: "avatar storage unavailable", Err: err}
+		}
+	}
+
+	url := s.store.URL(prefix)
+	previous, err := s.userRepo.UpdateAvatar(userID, &prefix, &url, actor)
+	if err != nil {
+		s.deleteBlobs(prefix)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, translateError(err)
+	}
+	if previous.AvatarKey != nil {
+		s.deleteBlobs(*previous.AvatarKey)
+	}
+
+	return s.reload(userID)
+}
+
+// Delete removes the user's avatar, if any
+func (s *avatarService) Delete(userID uint, actor model.AuditActor) (*model.User, error) {
+	previous, err := s.userRepo.UpdateAvatar(userID, nil, nil, actor)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, translateError(err)
+	}
+	if previous.AvatarKey != nil {
+		s.deleteBlobs(*previous.AvatarKey)
+	}
+
+	return s.reload(userID)
+}
+
+func (s *avatarService) reload(userID uint) (*model.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return user, nil
+}
+
+func (s *avatarService) deleteBlobs(prefix string) {
+	deleteAvatarBlobs(s.store, prefix, s.logger)
+}
+
+// deleteAvatarBlobs removes every thumbnail under prefix. Failures only leave
+// unreferenced files behind, so they are logged rather than returned.
+func deleteAvatarBlobs(store storage.BlobStore, prefix string, logger *zap.Logger) {
+	ctx := context.Background()
+	for _, size := range model.AvatarSizes {
+		if err := store.Delete(ctx, prefix+"/"+model.AvatarFile(size)); err != nil {
+			logger.Error("Failed to delete avatar", zap.String("key", prefix), zap.Error(err))
+		}
+	}
+}
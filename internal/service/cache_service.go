@@ -0,0 +1,154 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"example/internal/repository"
+	"example/pkg/cache"
+	"slices"
+	"time"
+)
+
+// sensitiveCacheFields are masked when cached values are inspected
+var sensitiveCacheFields = []string{"password"}
+
+// CacheEntry is a cached value as seen through the admin inspection tools
+type CacheEntry struct {
+	Key   string
+	Value json.RawMessage
+	TTL   time.Duration
+}
+
+// CacheService defines the interface for cache administration operations.
+// Keys are given without their namespace, e.g. "user:42".
+type CacheService interface {
+	WarmUsers(recent int) (int, error)
+	Lookup(key string) (*CacheEntry, error)
+	TTL(key string) (time.Duration, error)
+	Evict(key string) error
+}
+
+type cacheService struct {
+	userRepo     repository.UserRepository
+	cacheManager cache.Manager
+}
+
+func NewCacheService(userRepo repository.UserRepository, cacheManager cache.Manager) CacheService {
+	return &cacheService{
+		userRepo:     userRepo,
+		cacheManager: cacheManager,
+	}
+}
+
+// WarmUsers pre-populates the user cache, either for every user or only for
+// the ones who logged in most recently when recent > 0
+func (s *cacheService) WarmUsers(recent int) (int, error) {
+	warmed, err := s.userRepo.WarmCache(recent)
+	return warmed, translateCacheError(err)
+}
+
+func (s *cacheService) Lookup(key string) (*CacheEntry, error) {
+	ctx := context.Background()
+	fullKey := s.cacheManager.Keys().Key(key)
+
+	var value json.RawMessage
+	if err := s.cacheManager.Get(ctx, fullKey, &value); err != nil {
+		return nil, translateCacheError(err)
+	}
+
+	ttl, err := s.cacheManager.TTL(ctx, fullKey)
+	if err != nil {
+		return nil, translateCacheError(err)
+	}
+
+	return &CacheEntry{
+		Key:   key,
+		Value: maskSensitiveFields(value),
+		TTL:   ttl,
+	}, nil
+}
+
+func (s *cacheService) TTL(key string) (time.Duration, error) {
+	ttl, err := s.cacheManager.TTL(context.Background(), s.cacheManager.Keys().Key(key))
+	return ttl, translateCacheError(err)
+}
+
+func (s *cacheService) Evict(key string) error {
+	return translateCacheError(s.cacheManager.Delete(context.Background(), s.cacheManager.Keys().Key(key)))
+}
+
+// translateCacheError converts cache errors into domain errors
+func translateCacheError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case cache.IsNotFound(err):
+		return &NotFoundError{Message: "cache entry not found", Err: err}
+	case errors.Is(err, cache.ErrUnavailable):
+		return &UnavailableError{Message: "cache unavailable", Err: err}
+	default:
+		return translateError(err)
+	}
+}
+
+// maskSensitiveFields hides secrets such as password hashes in cached JSON,
+// at any depth, so lists of users are masked as well as single users.
+// Anything else is returned unchanged.
+func maskSensitiveFields(value json.RawMessage) json.RawMessage {
+	if masked, ok := maskValue(value); ok {
+		return masked
+	}
+	return value
+}
+
+// maskValue masks the sensitive fields of a JSON value and reports whether it
+// found any
+func maskValue(value json.RawMessage) (json.RawMessage, bool) {
+	trimmed := bytes.TrimSpace(value)
+	if len(trimmed) == 0 {
+		return value, false
+	}
+
+	masked := false
+	var result interface{}
+	switch trimmed[0] {
+	case '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return value, false
+		}
+		for name, field := range fields {
+			if slices.Contains(sensitiveCacheFields, name) {
+				fields[name] = json.RawMessage(`"[redacted]"`)
+				masked = true
+			} else if maskedField, ok := maskValue(field); ok {
+				fields[name] = maskedField
+				masked = true
+			}
+		}
+		result = fields
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return value, false
+		}
+		for i, item := range items {
+			if maskedItem, ok := maskValue(item); ok {
+				items[i] = maskedItem
+				masked = true
+			}
+		}
+		result = items
+	}
+	if !masked {
+		return value, false
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return value, false
+	}
+	return encoded, true
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMaskSensitiveFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{
+			name:  "user",
+			value: `{"name":"John","password":"$2a$10$hash"}`,
+			want:  `{"name":"John","password":"[redacted]"}`,
+		},
+		{
+			name:  "list page",
+			value: `{"users":[{"id":1,"password":"$2a$10$a"},{"id":2,"password":"$2a$10$b"}],"has_more":true}`,
+			want:  `{"has_more":true,"users":[{"id":1,"password":"[redacted]"},{"id":2,"password":"[redacted]"}]}`,
+		},
+		{
+			name:  "search results",
+			value: `[{"user":{"id":1,"password":"$2a$10$a"},"rank":0.5}]`,
+			want:  `[{"rank":0.5,"user":{"id":1,"password":"[redacted]"}}]`,
+		},
+		{
+			name:  "nothing sensitive is left as is",
+			value: `{"b":1, "a":[1,2]}`,
+			want:  `{"b":1, "a":[1,2]}`,
+		},
+		{
+			name:  "scalar",
+			value: `"active"`,
+			want:  `"active"`,
+		},
+		{
+			name:  "invalid JSON",
+			value: `{"password":`,
+			want:  `{"password":`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := maskSensitiveFields(json.RawMessage(tt.value))
+			if string(got) != tt.want {
+				t.Errorf("maskSensitiveFields(%s) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"example/internal/config"
+	"strings"
+)
+
+// emailProviderRule describes how a mail provider treats the local part of
+// its addresses, so variants delivered to the same mailbox map to one account
+type emailProviderRule struct {
+	// domain replaces aliases of the provider's main domain
+	domain    string
+	stripDots bool
+	// stripTag drops everything from the first + on
+	stripTag bool
+}
+
+var emailProviderRules = map[string]emailProviderRule{
+	"gmail.com":      {domain: "gmail.com", stripDots: true, stripTag: true},
+	"googlemail.com": {domain: "gmail.com", stripDots: true, stripTag: true},
+	"outlook.com":    {stripTag: true},
+	"hotmail.com":    {stripTag: true},
+	"live.com":       {stripTag: true},
+	"icloud.com":     {stripTag: true},
+	"me.com":         {domain: "icloud.com", stripTag: true},
+	"fastmail.com":   {stripTag: true},
+	"protonmail.com": {domain: "proton.me", stripTag: true},
+	"proton.me":      {stripTag: true},
+}
+
+// EmailNormalizer brings emails to the form they are stored and looked up in
+type EmailNormalizer struct {
+	// ProviderRules applies provider-specific rules such as ignoring dots in
+	// Gmail addresses. The user's stored email is changed accordingly.
+	ProviderRules bool
+}
+
+func NewEmailNormalizer(cfg *config.UsersConfig) EmailNormalizer {
+	return EmailNormalizer{
+		ProviderRules: cfg.EmailProviderRules,
+	}
+}
+
+// Normalize trims the email and lowercases its domain, which is always case
+// insensitive. The local part keeps its case as typed; uniqueness and lookups
+// ignore it anyway.
+func (n EmailNormalizer) Normalize(email string) string {
+	email = strings.TrimSpace(email)
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], strings.ToLower(email[at+1:])
+
+	if n.ProviderRules {
+		if rule, ok := emailProviderRules[domain]; ok {
+			local = strings.ToLower(local)
+			if rule.stripTag {
+				if i := strings.Index(local, "+"); i > 0 {
+					local = local[:i]
+				}
+			}
+			if rule.stripDots {
+				local = strings.ReplaceAll(local, ".", "")
+			}
+			if rule.domain != "" {
+				domain = rule.domain
+			}
+		}
+	}
+
+	return local + "@" + domain
+}
+
+// Key is the identity of an email: two emails with the same key belong to
+// the same account
+func (n EmailNormalizer) Key(email string) string {
+	return strings.ToLower(n.Normalize(email))
+}
+
+// Hash is a digest of the email's identity, for cache keys that shouldn't
+// hold the email itself
+func (n EmailNormalizer) Hash(email string) string {
+	sum := sha256.Sum256([]byte(n.Key(email)))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,61 @@
+package service
+
+import "testing"
+
+func TestEmailNormalizerNormalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		providerRules bool
+		email         string
+		want          string
+	}{
+		{name: "trims and lowercases the domain", email: "  John.Doe@Example.COM ", want: "John.Doe@example.com"},
+		{name: "no at sign", email: " john ", want: "john"},
+		{name: "last at sign splits", email: `"a@b"@Example.com`, want: `"a@b"@example.com`},
+		{name: "gmail left as is without provider rules", email: "John.Doe+news@Gmail.com", want: "John.Doe+news@gmail.com"},
+		{name: "gmail dots and tag", providerRules: true, email: "John.Doe+news@Gmail.com", want: "johndoe@gmail.com"},
+		{name: "googlemail alias", providerRules: true, email: "j.doe@googlemail.com", want: "jdoe@gmail.com"},
+		{name: "leading plus is kept", providerRules: true, email: "+john@gmail.com", want: "+john@gmail.com"},
+		{name: "outlook keeps dots", providerRules: true, email: "John.Doe+x@outlook.com", want: "john.doe@outlook.com"},
+		{name: "me alias", providerRules: true, email: "john+x@me.com", want: "john@icloud.com"},
+		{name: "protonmail alias", providerRules: true, email: "john+x@protonmail.com", want: "john@proton.me"},
+		{name: "other domains keep tags and case", providerRules: true, email: "John.Doe+x@example.com", want: "John.Doe+x@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := EmailNormalizer{ProviderRules: tt.providerRules}
+			if got := n.Normalize(tt.email); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailNormalizerKey(t *testing.T) {
+	tests := []struct {
+		name          string
+		providerRules bool
+		a, b          string
+		same          bool
+	}{
+		{name: "case", a: "John@Example.com", b: "john@example.com", same: true},
+		{name: "gmail dots without provider rules", a: "john.doe@gmail.com", b: "johndoe@gmail.com", same: false},
+		{name: "gmail dots", providerRules: true, a: "john.doe@gmail.com", b: "johndoe@gmail.com", same: true},
+		{name: "gmail tag", providerRules: true, a: "John+news@gmail.com", b: "john@googlemail.com", same: true},
+		{name: "tag on other domains", providerRules: true, a: "john+news@example.com", b: "john@example.com", same: false},
+		{name: "different mailboxes", providerRules: true, a: "john@gmail.com", b: "jane@gmail.com", same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := EmailNormalizer{ProviderRules: tt.providerRules}
+			if same := n.Key(tt.a) == n.Key(tt.b); same != tt.same {
+				t.Errorf("Key(%q) == Key(%q) is %v, want %v", tt.a, tt.b, same, tt.same)
+			}
+			if same := n.Hash(tt.a) == n.Hash(tt.b); same != tt.same {
+				t.Errorf("Hash(%q) == Hash(%q) is %v, want %v", tt.a, tt.b, same, tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"example/internal/config"
+	"example/internal/model"
+)
+
+func newTestEmailVerificationService(secret string) *emailVerificationService {
+	return NewEmailVerificationService(nil, nil, EmailNormalizer{}, &config.AuthConfig{SecretKey: secret}, "").(*emailVerificationService)
+}
+
+func TestEmailVerificationToken(t *testing.T) {
+	s := newTestEmailVerificationService("secret")
+	now := time.Unix(1_700_000_000, 0)
+	user := &model.User{Email: "john:doe@example.com"}
+	user.ID = 42
+
+	valid := s.sign(user, now.Add(time.Hour))
+	payload, signature, _ := strings.Cut(valid, ".")
+	// A payload for another user signed with the valid signature
+	forged := base64.RawURLEncoding.EncodeToString([]byte("43:1700003600:john:doe@example.com")) + "." + signature
+
+	tests := []struct {
+		name    string
+		service *emailVerificationService
+		token   string
+		now     time.Time
+		wantErr bool
+	}{
+		{name: "valid", service: s, token: valid, now: now},
+		{name: "just before expiry", service: s, token: valid, now: now.Add(time.Hour - time.Second)},
+		{name: "expired", service: s, token: valid, now: now.Add(time.Hour), wantErr: true},
+		{name: "other secret", service: newTestEmailVerificationService("other"), token: valid, now: now, wantErr: true},
+		{name: "forged payload", service: s, token: forged, now: now, wantErr: true},
+		{name: "truncated signature", service: s, token: valid[:len(valid)-2], now: now, wantErr: true},
+		{name: "no signature", service: s, token: payload, now: now, wantErr: true},
+		{name: "not base64", service: s, token: "!!!." + signature, now: now, wantErr: true},
+		{name: "empty", service: s, token: "", now: now, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, email, err := tt.service.verify(tt.token, tt.now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidVerificationToken) {
+					t.Errorf("verify() error = %v, want %v", err, ErrInvalidVerificationToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify() error = %v", err)
+			}
+			if id != user.ID || email != user.Email {
+				t.Errorf("verify() = %d, %q, want %d, %q", id, email, user.ID, user.Email)
+			}
+		})
+	}
+}
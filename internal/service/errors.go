@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"example/pkg/validator"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Domain errors returned by services. Handlers pick the response status from
+// the error type and only ever show the message, never the wrapped cause.
+
+// NotFoundError is returned when the requested resource doesn't exist
+type NotFoundError struct {
+	Message string
+	Err     error
+}
+
+func (e *NotFoundError) Error() string { return e.Message }
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// ConflictError is returned when a request clashes with the current state,
+// such as a duplicate email or a stale version
+type ConflictError struct {
+	Message string
+	Err     error
+}
+
+func (e *ConflictError) Error() string { return e.Message }
+func (e *ConflictError) Unwrap() error { return e.Err }
+
+// ValidationError is returned when the input is invalid. Fields lists the
+// offending fields when they are known.
+type ValidationError struct {
+	Message string
+	Fields  []validator.ValidationError
+	Err     error
+}
+
+func (e *ValidationError) Error() string { return e.Message }
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// UnauthorizedError is returned when the caller isn't allowed to proceed with
+// the credentials they presented
+type UnauthorizedError struct {
+	Message string
+	Err     error
+}
+
+func (e *UnauthorizedError) Error() string { return e.Message }
+func (e *UnauthorizedError) Unwrap() error { return e.Err }
+
+// ForbiddenError is returned when the caller is known but isn't allowed to
+// perform the action
+type ForbiddenError struct {
+	Message string
+	Err     error
+}
+
+func (e *ForbiddenError) Error() string { return e.Message }
+func (e *ForbiddenError) Unwrap() error { return e.Err }
+
+// UnavailableError is returned when a dependency needed to serve the request
+// is down
+type UnavailableError struct {
+	Message string
+	Err     error
+}
+
+func (e *UnavailableError) Error() string { return e.Message }
+func (e *UnavailableError) Unwrap() error { return e.Err }
+
+// TooManyRequestsError is returned when the client has to wait before trying
+// again. RetryAfter is how long, 0 if unknown.
+type TooManyRequestsError struct {
+	Message    string
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *TooManyRequestsError) Error() string { return e.Message }
+func (e *TooManyRequestsError) Unwrap() error { return e.Err }
+
+// invalidFields returns a ValidationError listing the fields that failed
+func invalidFields(fields []validator.ValidationError) error {
+	return &ValidationError{Message: "validation failed", Fields: fields}
+}
+
+var (
+	// ErrUserNotFound is returned when the requested user doesn't exist
+	ErrUserNotFound = &NotFoundError{Message: "user not found"}
+	// ErrVersionConflict is returned when a user was modified since the
+	// version the caller based its update on
+	ErrVersionConflict = &ConflictError{Message: "user was modified by another request"}
+	// ErrEmailTaken is returned when another user already has the email
+	ErrEmailTaken = &ConflictError{Message: "email is already in use"}
+	// ErrInvalidPassword is returned when the current password given to
+	// confirm a change doesn't match
+	ErrInvalidPassword = &ValidationError{
+		Message: "current password is incorrect",
+		Fields:  []validator.ValidationError{{Field: "CurrentPassword", Tag: "password"}},
+	}
+	// ErrInvalidResetToken is returned when a password reset token is unknown,
+	// expired or was already used
+	ErrInvalidResetToken = &ValidationError{Message: "password reset link is invalid or has expired"}
+	// ErrInvalidVerificationToken is returned when an email verification link
+	// is malformed, expired or was issued for another email
+	ErrInvalidVerificationToken = &ValidationError{Message: "email verification link is invalid or has expired"}
+	// ErrInvalidCredentials is returned on login when the email is unknown or
+	// the password doesn't match
+	ErrInvalidCredentials = &UnauthorizedError{Message: "incorrect email or password"}
+	// ErrEmailNotVerified is returned on login when verification is required
+	// and the user hasn't confirmed their email yet
+	ErrEmailNotVerified = &UnauthorizedError{Message: "email address has not been verified"}
+	// ErrAccountSuspended is returned on login, and for tokens, of suspended
+	// users
+	ErrAccountSuspended = &UnauthorizedError{Message: "account is suspended"}
+	// ErrAccountDisabled is returned on login, and for tokens, of disabled
+	// users
+	ErrAccountDisabled = &UnauthorizedError{Message: "account is disabled"}
+	// ErrInvalidCursor is returned when a pagination cursor is malformed or
+	// doesn't match the requested sort order
+	ErrInvalidCursor = &ValidationError{Message: "invalid pagination cursor"}
+)
+
+// Postgres error codes translated into domain errors
+// (https://www.postgresql.org/docs/current/errcodes-appendix.html)
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+	pgNotNullViolation    = "23502"
+	pgStringTooLong       = "22001"
+	pgInvalidText         = "22P02"
+)
+
+// translateError converts storage errors into domain errors, keeping them as
+// the cause. Errors it doesn't recognize are returned unchanged and end up as
+// internal errors. Services check for the errors they can describe more
+// precisely, such as ErrUserNotFound, before falling back to this.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &NotFoundError{Message: "resource not found", Err: err}
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return &ConflictError{Message: "resource already exists", Err: err}
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return &ConflictError{Message: "referenced resource does not exist or is still in use", Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return &ConflictError{Message: "resource already exists", Err: err}
+		case pgForeignKeyViolation:
+			return &ConflictError{Message: "referenced resource does not exist or is still in use", Err: err}
+		case pgCheckViolation, pgNotNullViolation, pgStringTooLong, pgInvalidText:
+			return &ValidationError{Message: "invalid value", Err: err}
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"example/internal/config"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/cache"
+	"example/pkg/logger"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLockoutAccountThreshold = 5
+	defaultLockoutIPThreshold      = 20
+	defaultLockoutWindow           = 15 * time.Minute
+	defaultLockoutDuration         = time.Minute
+	defaultLockoutMaxDuration      = time.Hour
+	defaultLockoutResetAfter       = 24 * time.Hour
+
+	// lockoutTimeout bounds every Redis round trip, so a slow Redis delays
+	// logins by at most this much
+	lockoutTimeout = 500 * time.Millisecond
+)
+
+// ErrLoginLocked is returned for every login attempt while the account or the
+// client IP is locked, whether the email has an account or not and whether the
+// password is right or not
+var ErrLoginLocked = &TooManyRequestsError{Message: "too many failed login attempts, please try again later"}
+
+// lockoutFailureScript adds a failure to the sliding window of KEYS[1] and, once
+// the window holds the threshold, locks KEYS[2] for the base duration doubled
+// for every earlier lock counted in KEYS[3]. Returns the lock duration in
+// milliseconds, 0 if not locked.
+//
+// ARGV: now (ms), unique member, window (ms), threshold, base duration (ms),
+// max duration (ms), reset after (ms)
+var lockoutFailureScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+redis.call('ZADD', KEYS[1], now, ARGV[2])
+redis.call('PEXPIRE', KEYS[1], window)
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[4]) then
+	return 0
+end
+
+local level = redis.call('INCR', KEYS[3])
+local duration = tonumber(ARGV[6])
+if level <= 32 then
+	duration = math.min(tonumber(ARGV[5]) * 2 ^ (level - 1), duration)
+end
+duration = math.floor(duration)
+redis.call('PEXPIRE', KEYS[3], duration + tonumber(ARGV[7]))
+redis.call('SET', KEYS[2], '1', 'PX', duration)
+redis.call('DEL', KEYS[1])
+return duration
+`)
+
+// LoginLockoutService defines the interface for brute-force protection of
+// logins. Failed logins are counted per account and per client IP; too many
+// within the window lock either for a while.
+//
+// Redis errors never block a login: they are logged and the attempt is let
+// through, so the API keeps working without Redis. While the cache circuit
+// breaker is open Redis isn't called at all, so a Redis outage doesn't slow
+// every login down by the Redis timeouts.
+type LoginLockoutService interface {
+	// Check returns ErrLoginLocked, carrying the remaining lock time, if
+	// logins for the email or from the IP are locked
+	Check(email, ip string) error
+	// Failure counts a failed login for the email and the IP, locking either
+	// once it reached its threshold. Emails without an account are counted
+	// all the same, so locking doesn't tell which emails have one.
+	Failure(email string, actor model.AuditActor)
+	// Success forgets the failed logins of the email's account
+	Success(email string)
+	// Unlock lifts the lock of the user's account and forgets its failed
+	// logins, including locks taken under an earlier email of the user. The
+	// actor is the admin unlocking it, without a user when unlocked from the
+	// command line.
+	Unlock(userID uint, actor model.AuditActor) error
+}
+
+type loginLockoutService struct {
+	client          redis.UniversalClient
+	cacheState      cache.StateReporter
+	keys            cache.KeyBuilder
+	emailNormalizer EmailNormalizer
+	userRepo        repository.UserRepository
+	auditService    AuditService
+
+	accountThreshold int
+	ipThreshold      int
+	window           time.Duration
+	duration         time.Duration
+	maxDuration      time.Duration
+	resetAfter       time.Duration
+
+	logger *zap.Logger
+}
+
+// NewLoginLockoutService creates the lockout service. cacheState is the cache
+// circuit breaker, nil where Redis is expected to be up, as on the command
+// line.
+func NewLoginLockoutService(
+	client redis.UniversalClient,
+	cacheState cache.StateReporter,
+	keys cache.KeyBuilder,
+	emailNormalizer EmailNormalizer,
+	userRepo repository.UserRepository,
+	auditService AuditService,
+	cfg *config.AuthConfig,
+) LoginLockoutService {
+	s := &loginLockoutService{
+		client:           client,
+		cacheState:       cacheState,
+		keys:             keys,
+		emailNormalizer:  emailNormalizer,
+		userRepo:         userRepo,
+		auditService:     auditService,
+		accountThreshold: cfg.LockoutAccountThreshold,
+		ipThreshold:      cfg.LockoutIPThreshold,
+		window:           cfg.LockoutWindow,
+		duration:         cfg.LockoutDuration,
+		maxDuration:      cfg.LockoutMaxDuration,
+		resetAfter:       cfg.LockoutResetAfter,
+		logger:           logger.GetLogger().With(zap.String("component", "login-lockout-service")),
+	}
+	if s.accountThreshold <= 0 {
+		s.accountThreshold = defaultLockoutAccountThreshold
+	}
+	if s.ipThreshold <= 0 {
+		s.ipThreshold = defaultLockoutIPThreshold
+	}
+	if s.window <= 0 {
+		s.window = defaultLockoutWindow
+	}
+	if s.duration <= 0 {
+		s.duration = defaultLockoutDuration
+	}
+	if s.maxDuration < s.duration {
+		s.maxDuration = max(s.duration, defaultLockoutMaxDuration)
+	}
+	if s.resetAfter <= 0 {
+		s.resetAfter = defaultLockoutResetAfter
+	}
+	return s
+}
+
+// lockoutKeys are the keys tracking one account or IP. They share a hash tag
+// so the script touching all of them runs on a single Redis Cluster slot.
+type lockoutKeys struct {
+	failures string
+	locked   string
+	level    string
+}
+
+func (s *loginLockoutService) lockoutKeys(kind, id string) lockoutKeys {
+	tag := "{" + kind + ":" + id + "}"
+	return lockoutKeys{
+		failures: s.keys.Key("login", tag, "failures"),
+		locked:   s.keys.Key("login", tag, "locked"),
+		level:    s.keys.Key("login", tag, "level"),
+	}
+}
+
+// accountID identifies the account of an email in the lockout keys. It hashes
+// the email's identity, so emails don't end up in Redis and any spelling of the
+// same email shares a counter.
+func (s *loginLockoutService) accountID(email string) string {
+	return s.emailNormalizer.Hash(email)
+}
+
+func (s *loginLockoutService) accountKeys(email string) lockoutKeys {
+	return s.lockoutKeys("account", s.accountID(email))
+}
+
+// lockedAccountsKey is the set of account IDs the user was locked under.
+// Unlock clears them all, as the user's email, or how it normalizes, may have
+// changed since.
+func (s *loginLockoutService) lockedAccountsKey(userID uint) string {
+	return s.keys.Key("login", "{user:"+strconv.FormatUint(uint64(userID), 10)+"}", "accounts")
+}
+
+func (s *loginLockoutService) ipKeys(ip string) lockoutKeys {
+	return s.lockoutKeys("ip", ip)
+}
+
+// redisDown reports whether the cache circuit breaker is open, in which case
+// Redis calls are skipped
+func (s *loginLockoutService) redisDown() bool {
+	return s.cacheState != nil && s.cacheState.State() == cache.StateOpen
+}
+
+func (s *loginLockoutService) Check(email, ip string) error {
+	if s.redisDown() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lockoutTimeout)
+	defer cancel()
+
+	pipe := s.client.Pipeline()
+	account := pipe.PTTL(ctx, s.accountKeys(email).locked)
+	client := pipe.PTTL(ctx, s.ipKeys(ip).locked)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		s.logger.Error("Failed to check login lockout", zap.Error(err))
+		return nil
+	}
+
+	// PTTL is negative for keys that don't exist
+	retryAfter := max(account.Val(), client.Val())
+	if retryAfter <= 0 {
+		return nil
+	}
+	return &TooManyRequestsError{Message: ErrLoginLocked.Message, RetryAfter: retryAfter, Err: ErrLoginLocked}
+}
+
+func (s *loginLockoutService) Failure(email string, actor model.AuditActor) {
+	if s.redisDown() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lockoutTimeout)
+	defer cancel()
+
+	member := strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+
+	accountLock, err := s.record(ctx, s.accountKeys(email), member, s.accountThreshold)
+	if err != nil {
+		s.logger.Error("Failed to record failed login for account", zap.Error(err))
+	}
+	if accountLock > 0 {
+		s.logger.Warn("Account locked after failed logins", zap.Duration("duration", accountLock), zap.String("ip", actor.IP))
+		userID := s.recordLocked(email, "account", accountLock, actor)
+		s.rememberLock(ctx, userID, s.accountID(email))
+	}
+
+	ipLock, err := s.record(ctx, s.ipKeys(actor.IP), member, s.ipThreshold)
+	if err != nil {
+		s.logger.Error("Failed to record failed login for IP", zap.Error(err))
+	}
+	if ipLock > 0 {
+		s.logger.Warn("IP locked after failed logins", zap.Duration("duration", ipLock), zap.String("ip", actor.IP))
+		s.recordLocked(email, "ip", ipLock, actor)
+	}
+}
+
+// record runs lockoutFailureScript for keys and returns the lock it started, if
+// any
+func (s *loginLockoutService) record(ctx context.Context, keys lockoutKeys, member string, threshold int) (time.Duration, error) {
+	ms, err := lockoutFailureScript.Run(ctx, s.client,
+		[]string{keys.failures, keys.locked, keys.level},
+		time.Now().UnixMilli(),
+		member,
+		s.window.Milliseconds(),
+		threshold,
+		s.duration.Milliseconds(),
+		s.maxDuration.Milliseconds(),
+		s.resetAfter.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// recordLocked records the start of a lock in the audit log, attributed to
+// the user with the email if there is one, and returns that user's ID. Like
+// failed logins, the email itself isn't recorded.
+func (s *loginLockoutService) recordLocked(email, scope string, duration time.Duration, actor model.AuditActor) uint {
+	var targetID uint
+	if user, err := s.userRepo.GetByEmail(s.emailNormalizer.Normalize(email)); err == nil && user != nil {
+		targetID = user.ID
+	}
+
+	event := model.NewAuditEvent(model.AuditAccountLocked, actor, targetID)
+	event.Metadata = model.AuditMetadata{
+		"scope":    scope,
+		"duration": duration.String(),
+	}
+	s.auditService.Record(event)
+	return targetID
+}
+
+// rememberLock adds the account the user was just locked under to their
+// locked accounts. The set lives as long as the lock level does.
+func (s *loginLockoutService) rememberLock(ctx context.Context, userID uint, account string) {
+	if userID == 0 {
+		return
+	}
+
+	key := s.lockedAccountsKey(userID)
+	pipe := s.client.Pipeline()
+	pipe.SAdd(ctx, key, account)
+	pipe.PExpire(ctx, key, s.maxDuration+s.resetAfter)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Error("Failed to remember locked account", zap.Uint("user_id", userID), zap.Error(err))
+	}
+}
+
+func (s *loginLockoutService) Success(email string) {
+	if s.redisDown() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lockoutTimeout)
+	defer cancel()
+
+	keys := s.accountKeys(email)
+	if err := s.client.Del(ctx, keys.failures, keys.level).Err(); err != nil {
+		s.logger.Error("Failed to reset failed logins", zap.Error(err))
+	}
+}
+
+func (s *loginLockoutService) Unlock(userID uint, actor model.AuditActor) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return translateError(err)
+	}
+
+	if s.redisDown() {
+		return &UnavailableError{Message: "login lockout is unavailable, please try again later", Err: cache.ErrUnavailable}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lockoutTimeout)
+	defer cancel()
+
+	lockedKey := s.lockedAccountsKey(userID)
+	accounts, err := s.client.SMembers(ctx, lockedKey).Result()
+	if err != nil {
+		s.logger.Error("Failed to get locked accounts", zap.Uint("user_id", userID), zap.Error(err))
+		return &UnavailableError{Message: "login lockout is unavailable, please try again later", Err: err}
+	}
+	accounts = append(accounts, s.accountID(user.Email))
+
+	// Each account's keys share a Redis Cluster slot, but the accounts don't
+	pipe := s.client.Pipeline()
+	for _, account := range accounts {
+		keys := s.lockoutKeys("account", account)
+		pipe.Del(ctx, keys.locked, keys.failures, keys.level)
+	}
+	pipe.Del(ctx, lockedKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Error("Failed to unlock account", zap.Uint("user_id", userID), zap.Error(err))
+		return &UnavailableError{Message: "login lockout is unavailable, please try again later", Err: err}
+	}
+
+	s.auditService.Record(model.NewAuditEvent(model.AuditUserUnlocked, actor, userID))
+	s.logger.Info("Account unlocked", zap.Uint("user_id", userID), zap.Uintp("unlocked_by", actor.UserID))
+	return nil
+}
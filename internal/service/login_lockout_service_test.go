@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"example/internal/config"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/cache"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type fixedCacheState cache.State
+
+func (s fixedCacheState) State() cache.State { return cache.State(s) }
+
+// fakeLockoutUserRepo only knows the user with ID 1. Other methods aren't used
+// by the lockout.
+type fakeLockoutUserRepo struct {
+	repository.UserRepository
+}
+
+func (r fakeLockoutUserRepo) GetByID(id uint) (*model.User, error) {
+	if id != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	user := &model.User{Email: "john@example.com"}
+	user.ID = id
+	return user, nil
+}
+
+// newTestLockoutService talks to a Redis that isn't there, so every Redis
+// call fails quickly
+func newTestLockoutService(state cache.State, providerRules bool) *loginLockoutService {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 50 * time.Millisecond})
+	return NewLoginLockoutService(
+		client,
+		fixedCacheState(state),
+		cache.NewKeyBuilder("app", 1),
+		EmailNormalizer{ProviderRules: providerRules},
+		fakeLockoutUserRepo{},
+		nil,
+		&config.AuthConfig{},
+	).(*loginLockoutService)
+}
+
+// hashTag returns the part of a key Redis Cluster picks the slot from
+func hashTag(key string) string {
+	start := strings.Index(key, "{")
+	end := strings.Index(key[start+1:], "}")
+	if start < 0 || end <= 0 {
+		return key
+	}
+	return key[start+1 : start+1+end]
+}
+
+func TestLockoutAccountKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		providerRules bool
+		a, b          string
+		same          bool
+	}{
+		{name: "case and spaces", a: " John@Example.com", b: "john@example.com", same: true},
+		{name: "gmail dots with provider rules", providerRules: true, a: "j.doe+x@gmail.com", b: "jdoe@gmail.com", same: true},
+		{name: "gmail dots without provider rules", a: "j.doe@gmail.com", b: "jdoe@gmail.com", same: false},
+		{name: "different emails", a: "john@example.com", b: "jane@example.com", same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestLockoutService(cache.StateClosed, tt.providerRules)
+			a, b := s.accountKeys(tt.a), s.accountKeys(tt.b)
+			if same := a == b; same != tt.same {
+				t.Errorf("accountKeys(%q) == accountKeys(%q) is %v, want %v", tt.a, tt.b, same, tt.same)
+			}
+
+			for _, keys := range []lockoutKeys{a, s.ipKeys("192.0.2.1")} {
+				if strings.Contains(keys.locked, "@") {
+					t.Errorf("key %q holds the email", keys.locked)
+				}
+				tag := hashTag(keys.failures)
+				if tag == keys.failures || hashTag(keys.locked) != tag || hashTag(keys.level) != tag {
+					t.Errorf("keys %+v don't share a hash tag", keys)
+				}
+			}
+		})
+	}
+}
+
+func TestLockoutWithoutRedis(t *testing.T) {
+	tests := []struct {
+		name  string
+		state cache.State
+	}{
+		{name: "breaker open", state: cache.StateOpen},
+		{name: "Redis unreachable", state: cache.StateClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestLockoutService(tt.state, false)
+			actor := model.AuditActor{IP: "192.0.2.1"}
+
+			// Logins go through however often they fail
+			for i := 0; i < s.accountThreshold+1; i++ {
+				s.Failure("john@example.com", actor)
+			}
+			if err := s.Check("john@example.com", actor.IP); err != nil {
+				t.Errorf("Check() error = %v, want nil", err)
+			}
+			s.Success("john@example.com")
+
+			var unavailable *UnavailableError
+			if err := s.Unlock(1, actor); !errors.As(err, &unavailable) {
+				t.Errorf("Unlock() error = %v, want %T", err, unavailable)
+			}
+			if err := s.Unlock(2, actor); !errors.Is(err, ErrUserNotFound) {
+				t.Errorf("Unlock() of unknown user error = %v, want %v", err, ErrUserNotFound)
+			}
+		})
+	}
+}
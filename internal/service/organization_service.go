@@ -0,0 +1,463 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"example/internal/config"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/cache"
+	"example/pkg/logger"
+	"example/pkg/mailer"
+	"example/pkg/validator"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInviteLimit      = 50
+	defaultInviteRateWindow = time.Hour
+)
+
+var (
+	// ErrOrganizationNotFound is returned when the organization doesn't exist
+	// or the user isn't one of its members
+	ErrOrganizationNotFound = &NotFoundError{Message: "organization not found"}
+	// ErrMemberNotFound is returned when the user isn't a member of the
+	// organization
+	ErrMemberNotFound = &NotFoundError{Message: "member not found"}
+	// ErrInvitationNotFound is returned when the email has no invitation to
+	// the organization
+	ErrInvitationNotFound = &NotFoundError{Message: "invitation not found"}
+	// ErrAlreadyMember is returned when inviting an email that belongs to a
+	// member or was already invited
+	ErrAlreadyMember = &ConflictError{Message: "user is already a member of the organization or was already invited"}
+	// ErrInvitationEmailUnverified is returned when accepting an invitation
+	// before verifying the email it was sent to
+	ErrInvitationEmailUnverified = &ForbiddenError{Message: "verify your email before accepting invitations"}
+	// ErrLastOwner is returned when removing or demoting the only owner
+	ErrLastOwner = &ConflictError{Message: "organization must keep at least one owner"}
+	// ErrCannotManageMembers is returned when a member without the owner or
+	// admin role tries to manage members
+	ErrCannotManageMembers = &ForbiddenError{Message: "only owners and admins can manage members"}
+	// ErrOwnersOnly is returned when an admin tries to make someone an owner
+	// or to change or remove an owner
+	ErrOwnersOnly = &ForbiddenError{Message: "only owners can manage owners"}
+	// ErrInvalidOrgRole is returned for an unknown organization role
+	ErrInvalidOrgRole = &ValidationError{
+		Message: "role must be owner, admin or member",
+		Fields:  []validator.ValidationError{{Field: "Role", Tag: "oneof", Value: "owner admin member"}},
+	}
+)
+
+// OrganizationService defines the interface for organization and membership
+// operations. Every method acts on behalf of a user, whose membership decides
+// what they may see and do.
+type OrganizationService interface {
+	Create(userID uint, name string) (*model.Organization, error)
+	Get(orgID, userID uint) (*model.Organization, error)
+	// ListForUser returns the memberships of the user
+	ListForUser(userID uint) ([]model.Membership, error)
+	// InvitationsForUser returns the invitations sent to the user's email,
+	// none until the email is verified
+	InvitationsForUser(userID uint) ([]model.Invitation, error)
+	ListMembers(orgID, userID uint) ([]model.Membership, error)
+	ListInvitations(orgID, userID uint) ([]model.Invitation, error)
+	// Invite invites the email to the organization. It answers the same
+	// whether the email has an account or not, so members can't use it to
+	// find out which emails do.
+	Invite(orgID, inviterID uint, email string, role model.OrgRole) (*model.Invitation, error)
+	// Accept turns the invitation sent to the user's email into a
+	// membership. The email must be verified, proving the user owns it.
+	Accept(orgID, userID uint) (*model.Membership, error)
+	// Decline deletes the invitation sent to the user's email
+	Decline(orgID, userID uint) error
+	// CancelInvitation deletes the invitation of the email
+	CancelInvitation(orgID, actorID uint, email string) error
+	SetRole(orgID, actorID, memberID uint, role model.OrgRole) (*model.Membership, error)
+	// RemoveMember removes a member. Members can always remove themselves,
+	// unless they are the last owner.
+	RemoveMember(orgID, actorID, memberID uint) error
+	// ActiveMembership returns the accepted membership of the user, or
+	// ErrMemberNotFound
+	ActiveMembership(orgID, userID uint) (*model.Membership, error)
+	// DefaultOrganization is the organization tokens are issued for when the
+	// user doesn't pick one: the one they joined first, 0 if none
+	DefaultOrganization(userID uint) (uint, error)
+}
+
+type organizationService struct {
+	orgRepo         repository.OrganizationRepository
+	userRepo        repository.UserRepository
+	mailer          mailer.Mailer
+	emailNormalizer EmailNormalizer
+	inviteLimiter   RateLimiter
+	appURL          string
+	logger          *zap.Logger
+}
+
+func NewOrganizationService(
+	orgRepo repository.OrganizationRepository,
+	userRepo repository.UserRepository,
+	mailer mailer.Mailer,
+	emailNormalizer EmailNormalizer,
+	cacheManager cache.Manager,
+	cfg *config.UsersConfig,
+	appURL string,
+) OrganizationService {
+	inviteLimit := cfg.InviteLimit
+	if inviteLimit <= 0 {
+		inviteLimit = defaultInviteLimit
+	}
+	inviteRateWindow := cfg.InviteRateWindow
+	if inviteRateWindow <= 0 {
+		inviteRateWindow = defaultInviteRateWindow
+	}
+
+	return &organizationService{
+		orgRepo:         orgRepo,
+		userRepo:        userRepo,
+		mailer:          mailer,
+		emailNormalizer: emailNormalizer,
+		inviteLimiter:   NewRateLimiter(cacheManager, "org-invite", inviteLimit, inviteRateWindow),
+		appURL:          strings.TrimRight(appURL, "/"),
+		logger:          logger.GetLogger().With(zap.String("component", "organization-service")),
+	}
+}
+
+func (s *organizationService) Create(userID uint, name string) (*model.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, invalidFields([]validator.ValidationError{{Field: "Name", Tag: "required"}})
+	}
+
+	now := time.Now()
+	org := &model.Organization{Name: name, CreatedBy: &userID}
+	owner := &model.Membership{
+		UserID:     userID,
+		Role:       model.OrgRoleOwner,
+		AcceptedAt: &now,
+	}
+	if err := s.orgRepo.Create(org, owner); err != nil {
+		return nil, translateError(err)
+	}
+
+	return org, nil
+}
+
+func (s *organizationService) Get(orgID, userID uint) (*model.Organization, error) {
+	if _, err := s.ActiveMembership(orgID, userID); err != nil {
+		return nil, s.hideOrganization(err)
+	}
+
+	org, err := s.orgRepo.GetByID(orgID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, translateError(err)
+	}
+	return org, nil
+}
+
+func (s *organizationService) ListForUser(userID uint) ([]model.Membership, error) {
+	memberships, err := s.orgRepo.ListForUser(userID)
+	return memberships, translateError(err)
+}
+
+func (s *organizationService) InvitationsForUser(userID uint) ([]model.Invitation, error) {
+	user, err := s.user(userID)
+	if err != nil {
+		return nil, err
+	}
+	// Anyone can sign up with any email, so the invitations are only shown
+	// to whoever proved owning it
+	if !user.EmailVerified() {
+		return []model.Invitation{}, nil
+	}
+
+	invitations, err := s.orgRepo.ListInvitationsForEmail(user.Email)
+	return invitations, translateError(err)
+}
+
+func (s *organizationService) ListMembers(orgID, userID uint) ([]model.Membership, error) {
+	if _, err := s.ActiveMembership(orgID, userID); err != nil {
+		return nil, s.hideOrganization(err)
+	}
+
+	memberships, err := s.orgRepo.ListMembers(orgID)
+	return memberships, translateError(err)
+}
+
+func (s *organizationService) ListInvitations(orgID, userID uint) ([]model.Invitation, error) {
+	if _, err := s.ActiveMembership(orgID, userID); err != nil {
+		return nil, s.hideOrganization(err)
+	}
+
+	invitations, err := s.orgRepo.ListInvitations(orgID)
+	return invitations, translateError(err)
+}
+
+// Invite stores an invitation for the email and lets its owner know by email,
+// in the background so the answer takes as long for emails with an account as
+// for those without. Members are the only emails refused, and those are
+// listed to members anyway.
+func (s *organizationService) Invite(orgID, inviterID uint, email string, role model.OrgRole) (*model.Invitation, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidOrgRole
+	}
+
+	inviter, err := s.manager(orgID, inviterID)
+	if err != nil {
+		return nil, err
+	}
+	if role == model.OrgRoleOwner && inviter.Role != model.OrgRoleOwner {
+		return nil, ErrOwnersOnly
+	}
+	if err := s.inviteLimiter.Allow(strconv.FormatUint(uint64(inviterID), 10)); err != nil {
+		return nil, err
+	}
+
+	email = s.emailNormalizer.Normalize(email)
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if user != nil {
+		if _, err := s.ActiveMembership(orgID, user.ID); err == nil {
+			return nil, ErrAlreadyMember
+		} else if !errors.Is(err, ErrMemberNotFound) {
+			return nil, err
+		}
+	}
+
+	invitation := &model.Invitation{
+		OrganizationID: orgID,
+		Email:          email,
+		Role:           role,
+		InvitedBy:      &inviterID,
+	}
+	if err := s.orgRepo.Invite(invitation); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrAlreadyMember
+		}
+		return nil, translateError(err)
+	}
+
+	go s.sendInvitation(*invitation)
+	return invitation, nil
+}
+
+// sendInvitation emails the invitation. It is listed with the organizations of
+// the email's owner either way, so failures are only logged.
+func (s *organizationService) sendInvitation(invitation model.Invitation) {
+	org, err := s.orgRepo.GetByID(invitation.OrganizationID)
+	if err != nil {
+		s.logger.Error("Failed to load organization for invitation", zap.Uint("organization_id", invitation.OrganizationID), zap.Error(err))
+		return
+	}
+
+	err = s.mailer.Send(context.Background(), mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You have been invited to join %s", org.Name),
+		Body: fmt.Sprintf("Hi,\n\n"+
+			"You have been invited to join %s. Log in, or sign up with this email address, to accept the invitation:\n\n"+
+			"%s/organizations/%d\n\n"+
+			"If you don't want to join, you can ignore this email.\n",
+			org.Name, s.appURL, org.ID),
+	})
+	if err != nil {
+		s.logger.Error("Failed to send invitation email", zap.Uint("organization_id", org.ID), zap.Error(err))
+	}
+}
+
+func (s *organizationService) Accept(orgID, userID uint) (*model.Membership, error) {
+	user, err := s.user(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.EmailVerified() {
+		return nil, ErrInvitationEmailUnverified
+	}
+
+	membership, err := s.orgRepo.Accept(orgID, userID, user.Email, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrInvitationNotFound
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			return nil, ErrAlreadyMember
+		}
+		return nil, translateError(err)
+	}
+	return membership, nil
+}
+
+func (s *organizationService) Decline(orgID, userID uint) error {
+	user, err := s.user(userID)
+	if err != nil {
+		return err
+	}
+	return s.deleteInvitation(orgID, user.Email)
+}
+
+func (s *organizationService) CancelInvitation(orgID, actorID uint, email string) error {
+	actor, err := s.manager(orgID, actorID)
+	if err != nil {
+		return err
+	}
+
+	email = s.emailNormalizer.Normalize(email)
+	invitation, err := s.orgRepo.GetInvitation(orgID, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvitationNotFound
+		}
+		return translateError(err)
+	}
+	if invitation.Role == model.OrgRoleOwner && actor.Role != model.OrgRoleOwner {
+		return ErrOwnersOnly
+	}
+
+	return s.deleteInvitation(orgID, email)
+}
+
+func (s *organizationService) deleteInvitation(orgID uint, email string) error {
+	if err := s.orgRepo.DeleteInvitation(orgID, email); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvitationNotFound
+		}
+		return translateError(err)
+	}
+	return nil
+}
+
+func (s *organizationService) SetRole(orgID, actorID, memberID uint, role model.OrgRole) (*model.Membership, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidOrgRole
+	}
+
+	actor, err := s.manager(orgID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.membership(orgID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role != model.OrgRoleOwner && (role == model.OrgRoleOwner || member.Role == model.OrgRoleOwner) {
+		return nil, ErrOwnersOnly
+	}
+
+	if err := s.orgRepo.UpdateRole(orgID, memberID, role); err != nil {
+		return nil, s.translateMembershipError(err)
+	}
+
+	return s.membership(orgID, memberID)
+}
+
+func (s *organizationService) RemoveMember(orgID, actorID, memberID uint) error {
+	if actorID != memberID {
+		actor, err := s.manager(orgID, actorID)
+		if err != nil {
+			return err
+		}
+		member, err := s.membership(orgID, memberID)
+		if err != nil {
+			return err
+		}
+		if member.Role == model.OrgRoleOwner && actor.Role != model.OrgRoleOwner {
+			return ErrOwnersOnly
+		}
+	}
+
+	if err := s.orgRepo.RemoveMember(orgID, memberID); err != nil {
+		return s.translateMembershipError(err)
+	}
+	return nil
+}
+
+func (s *organizationService) ActiveMembership(orgID, userID uint) (*model.Membership, error) {
+	membership, err := s.membership(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !membership.Active() {
+		return nil, ErrMemberNotFound
+	}
+	return membership, nil
+}
+
+func (s *organizationService) DefaultOrganization(userID uint) (uint, error) {
+	memberships, err := s.orgRepo.ListForUser(userID)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	// Accepted memberships are listed first, in the order they were joined
+	if len(memberships) == 0 || !memberships[0].Active() {
+		return 0, nil
+	}
+	return memberships[0].OrganizationID, nil
+}
+
+// user returns the user acting, or ErrUserNotFound
+func (s *organizationService) user(userID uint) (*model.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, translateError(err)
+	}
+	return user, nil
+}
+
+// membership returns the membership of the user, accepted or not
+func (s *organizationService) membership(orgID, userID uint) (*model.Membership, error) {
+	membership, err := s.orgRepo.GetMembership(orgID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, translateError(err)
+	}
+	return membership, nil
+}
+
+// manager returns the active membership of a user allowed to manage members.
+// Organizations the user isn't a member of are reported as not found.
+func (s *organizationService) manager(orgID, userID uint) (*model.Membership, error) {
+	membership, err := s.ActiveMembership(orgID, userID)
+	if err != nil {
+		return nil, s.hideOrganization(err)
+	}
+	if !membership.Role.CanManageMembers() {
+		return nil, ErrCannotManageMembers
+	}
+	return membership, nil
+}
+
+// hideOrganization reports a missing membership of the caller as a missing
+// organization, so non-members can't tell which organizations exist
+func (s *organizationService) hideOrganization(err error) error {
+	if errors.Is(err, ErrMemberNotFound) {
+		return ErrOrganizationNotFound
+	}
+	return err
+}
+
+func (s *organizationService) translateMembershipError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrLastOwner):
+		return ErrLastOwner
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrMemberNotFound
+	}
+	return translateError(err)
+}
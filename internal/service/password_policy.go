@@ -0,0 +1,74 @@
+package service
+
+import (
+	"example/internal/config"
+	"example/pkg/validator"
+	"strconv"
+	"unicode"
+)
+
+const defaultPasswordMinLength = 8
+
+// PasswordPolicy describes what a new password must look like
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+func NewPasswordPolicy(cfg *config.AuthConfig) PasswordPolicy {
+	policy := PasswordPolicy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+	}
+
+	if policy.MinLength <= 0 {
+		policy.MinLength = defaultPasswordMinLength
+	}
+
+	return policy
+}
+
+// Validate checks password against the policy and reports every rule it
+// breaks as a validation error on field
+func (p PasswordPolicy) Validate(field, password string) []validator.ValidationError {
+	var errors []validator.ValidationError
+
+	if len([]rune(password)) < p.MinLength {
+		errors = append(errors, validator.ValidationError{Field: field, Tag: "min", Value: strconv.Itoa(p.MinLength)})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		errors = append(errors, validator.ValidationError{Field: field, Tag: "uppercase"})
+	}
+	if p.RequireLower && !hasLower {
+		errors = append(errors, validator.ValidationError{Field: field, Tag: "lowercase"})
+	}
+	if p.RequireDigit && !hasDigit {
+		errors = append(errors, validator.ValidationError{Field: field, Tag: "digit"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		errors = append(errors, validator.ValidationError{Field: field, Tag: "symbol"})
+	}
+
+	return errors
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"example/internal/config"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/cache"
+	"example/pkg/logger"
+	"example/pkg/mailer"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPasswordResetTTL        = time.Hour
+	defaultPasswordResetIPLimit    = 10
+	defaultPasswordResetEmailLimit = 3
+	defaultPasswordResetRateWindow = time.Hour
+	resetTokenBytes                = 32
+)
+
+// PasswordResetService defines the interface for the forgotten password flow
+type PasswordResetService interface {
+	// RequestReset emails a reset link in the background and returns right
+	// away, so neither the outcome nor the time it takes tells whether the
+	// email has an account
+	RequestReset(email string)
+	ResetPassword(token, newPassword string, actor model.AuditActor) error
+}
+
+type passwordResetService struct {
+	userRepo        repository.UserRepository
+	tokenRepo       repository.PasswordResetRepository
+	mailer          mailer.Mailer
+	passwordPolicy  PasswordPolicy
+	emailNormalizer EmailNormalizer
+	emailLimiter    RateLimiter
+	ttl             time.Duration
+	appURL          string
+	logger          *zap.Logger
+}
+
+func NewPasswordResetService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.PasswordResetRepository,
+	mailer mailer.Mailer,
+	passwordPolicy PasswordPolicy,
+	emailNormalizer EmailNormalizer,
+	cacheManager cache.Manager,
+	cfg *config.AuthConfig,
+	appURL string,
+) PasswordResetService {
+	ttl := cfg.PasswordResetTTL
+	if ttl <= 0 {
+		ttl = defaultPasswordResetTTL
+	}
+	emailLimit := cfg.PasswordResetEmailLimit
+	if emailLimit <= 0 {
+		emailLimit = defaultPasswordResetEmailLimit
+	}
+
+	return &passwordResetService{
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		mailer:          mailer,
+		passwordPolicy:  passwordPolicy,
+		emailNormalizer: emailNormalizer,
+		emailLimiter:    NewRateLimiter(cacheManager, "password-reset-email", emailLimit, passwordResetRateWindow(cfg)),
+		ttl:             ttl,
+		appURL:          strings.TrimRight(appURL, "/"),
+		logger:          logger.GetLogger().With(zap.String("component", "password-reset-service")),
+	}
+}
+
+// NewPasswordResetIPLimiter limits how many password resets a client IP may
+// request
+func NewPasswordResetIPLimiter(cacheManager cache.Manager, cfg *config.AuthConfig) RateLimiter {
+	limit := cfg.PasswordResetIPLimit
+	if limit <= 0 {
+		limit = defaultPasswordResetIPLimit
+	}
+	return NewRateLimiter(cacheManager, "password-reset-ip", limit, passwordResetRateWindow(cfg))
+}
+
+func passwordResetRateWindow(cfg *config.AuthConfig) time.Duration {
+	if cfg.PasswordResetRateWindow <= 0 {
+		return defaultPasswordResetRateWindow
+	}
+	return cfg.PasswordResetRateWindow
+}
+
+// hashResetToken is how tokens are stored, so a database leak doesn't hand
+// out working reset links
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *passwordResetService) RequestReset(email string) {
+	go func() {
+		if err := s.sendReset(email); err != nil {
+			s.logger.Error("Failed to send password reset", zap.Error(err))
+		}
+	}()
+}
+
+// sendReset emails a reset link to the user with the given email. Unknown
+// emails and emails that were sent too many links lately are skipped.
+func (s *passwordResetService) sendReset(email string) error {
+	if err := s.emailLimiter.Allow(s.emailNormalizer.Hash(email)); err != nil {
+		s.logger.Warn("Password reset rate limit reached for email")
+		return nil
+	}
+
+	user, err := s.userRepo.GetByEmail(s.emailNormalizer.Normalize(email))
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		s.logger.Info("Password reset requested for unknown email")
+		return nil
+	}
+
+	raw := make([]byte, resetTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	err = s.tokenRepo.Create(&model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.appURL, url.QueryEscape(token))
+	err = s.mailer.Send(context.Background(), mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your account. If it was you, open the link below to choose a new password:\n\n"+
+			"%s\n\n"+
+			"The link expires in %s and can only be used once. If you didn't ask for a reset, you can ignore this email.\n",
+			user.Name, link, s.ttl),
+	})
+	if err != nil {
+		return fmt.Errorf("send email to user %d: %w", user.ID, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password for the user the token was issued to. The
+// token is used up even if setting the password then fails, and every other
+// token and session of the user stops working.
+func (s *passwordResetService) ResetPassword(token, newPassword string, actor model.AuditActor) error {
+	// Check the password first, so a rejected one doesn't use up the token
+	if validationErrs := s.passwordPolicy.Validate("NewPassword", newPassword); len(validationErrs) > 0 {
+		return invalidFields(validationErrs)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	resetToken, err := s.tokenRepo.Consume(hashResetToken(token), now)
+	if err != nil {
+		if errors.Is(err, repository.ErrResetTokenInvalid) {
+			return ErrInvalidResetToken
+		}
+		return translateError(err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(resetToken.UserID, string(hashedPassword), now, actor); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return translateError(err)
+	}
+
+	if err := s.tokenRepo.DeleteForUser(resetToken.UserID); err != nil {
+		// The remaining tokens expire on their own
+		s.logger.Error("Failed to delete password reset tokens", zap.Error(err))
+	}
+
+	s.logger.Info("Password reset", zap.Uint("user_id", resetToken.UserID))
+	return nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"example/internal/config"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/logger"
+	"example/pkg/storage"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ErrErasureChainBroken is returned by VerifyErasureReceipts when a receipt's
+// hash doesn't match its fields or the receipt before it
+var ErrErasureChainBroken = errors.New("erasure receipt chain is broken")
+
+// UserDataArchive is everything stored about a user, as handed to them on a
+// data export request
+type UserDataArchive struct {
+	ExportedAt     time.Time
+	User           *model.User
+	Roles          []model.Role
+	Memberships    []model.Membership
+	Invitations    []model.Invitation
+	PasswordResets []model.PasswordResetToken
+	// Avatar holds the stored avatar thumbnails, empty without an avatar
+	Avatar []AvatarFile
+	// AuditEvents are the events the user made or was the target of
+	AuditEvents []model.AuditEvent
+}
+
+// AvatarFile is a stored avatar thumbnail
+type AvatarFile struct {
+	Size int
+	Data []byte
+}
+
+// PrivacyService defines the interface for data subject requests: exporting
+// and erasing a user's personal data
+type PrivacyService interface {
+	// Export gathers everything stored about the user
+	Export(userID uint) (*UserDataArchive, error)
+	// EraseSelf erases the user's own data after checking their password
+	EraseSelf(userID uint, currentPassword string, actor model.AuditActor) (*model.ErasureReceipt, error)
+	// Erase erases the user's data on behalf of the actor
+	Erase(userID uint, actor model.AuditActor) (*model.ErasureReceipt, error)
+	// VerifyErasureReceipts checks the hash chain of every erasure receipt
+	// and returns how many were checked. A broken chain is reported with
+	// ErrErasureChainBroken naming the first receipt that doesn't match.
+	VerifyErasureReceipts() (int, error)
+}
+
+type privacyService struct {
+	userRepo          repository.UserRepository
+	roleRepo          repository.RoleRepository
+	orgRepo           repository.OrganizationRepository
+	passwordResetRepo repository.PasswordResetRepository
+	receiptRepo       repository.ErasureReceiptRepository
+	auditRepo         repository.AuditRepository
+	store             storage.BlobStore
+	emailHashKey      []byte
+	logger            *zap.Logger
+}
+
+func NewPrivacyService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	orgRepo repository.OrganizationRepository,
+	passwordResetRepo repository.PasswordResetRepository,
+	receiptRepo repository.ErasureReceiptRepository,
+	auditRepo repository.AuditRepository,
+	store storage.BlobStore,
+	cfg *config.AuthConfig,
+) PrivacyService {
+	emailHashKey := []byte(cfg.ErasureEmailKey)
+	if len(emailHashKey) == 0 {
+		// Derive a dedicated key, like email verification does
+		mac := hmac.New(sha256.New, []byte(cfg.SecretKey))
+		mac.Write([]byte("erasure-receipt"))
+		emailHashKey = mac.Sum(nil)
+	}
+
+	return &privacyService{
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		orgRepo:           orgRepo,
+		passwordResetRepo: passwordResetRepo,
+		receiptRepo:       receiptRepo,
+		auditRepo:         auditRepo,
+		store:             store,
+		emailHashKey:      emailHashKey,
+		logger:            logger.GetLogger().With(zap.String("component", "privacy-service")),
+	}
+}
+
+func (s *privacyService) Export(userID uint) (*UserDataArchive, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, translateError(err)
+	}
+
+	archive := &UserDataArchive{ExportedAt: time.Now().UTC(), User: user}
+	if archive.Roles, err = s.roleRepo.GetRoles(userID); err != nil {
+		return nil, translateError(err)
+	}
+	if archive.Memberships, err = s.orgRepo.ListForUser(userID); err != nil {
+		return nil, translateError(err)
+	}
+	// Invitations are stored by email, which is only known to be the user's
+	// once verified
+	if user.EmailVerified() {
+		if archive.Invitations, err = s.orgRepo.ListInvitationsForEmail(user.Email); err != nil {
+			return nil, translateError(err)
+		}
+	}
+	if archive.PasswordResets, err = s.passwordResetRepo.ListForUser(userID); err != nil {
+		return nil, translateError(err)
+	}
+	if archive.AuditEvents, err = s.auditRepo.ListForUser(userID); err != nil {
+		return nil, translateError(err)
+	}
+	if archive.Avatar, err = s.avatarFiles(user); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// avatarFiles loads the user's avatar thumbnails. A missing thumbnail is
+// skipped, as the user only ever sees the ones that were stored.
+func (s *privacyService) avatarFiles(user *model.User) ([]AvatarFile, error) {
+	if user.AvatarKey == nil {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	var files []AvatarFile
+	for _, size := range model.AvatarSizes {
+		data, err := s.store.Get(ctx, *user.AvatarKey+"/"+model.AvatarFile(size))
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			s.logger.Error("Failed to load avatar for export", zap.Uint("user_id", user.ID), zap.Error(err))
+			return nil, &UnavailableError{Message: "avatar storage unavailable", Err: err}
+		}
+		files = append(files, AvatarFile{Size: size, Data: data})
+	}
+	return files, nil
+}
+
+func (s *privacyService) EraseSelf(userID uint, currentPassword string, actor model.AuditActor) (*model.ErasureReceipt, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, translateError(err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	return s.Erase(userID, actor)
+}
+
+func (s *privacyService) Erase(userID uint, actor model.AuditActor) (*model.ErasureReceipt, error) {
+	previous, receipt, err := s.userRepo.Erase(userID, actor, time.Now(), s.emailHashKey)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrUserNotFound
+		case errors.Is(err, repository.ErrLastOwner):
+			return nil, ErrLastOwner
+		}
+		return nil, translateError(err)
+	}
+
+	s.logger.Info("User erased", zap.Uint("user_id", userID), zap.Uint("receipt_id", receipt.ID))
+	if previous.AvatarKey != nil {
+		deleteAvatarBlobs(s.store, *previous.AvatarKey, s.logger)
+	}
+
+	return receipt, nil
+}
+
+func (s *privacyService) VerifyErasureReceipts() (int, error) {
+	checked := 0
+	prevHash := ""
+	err := s.receiptRepo.Each(func(receipt model.ErasureReceipt) error {
+		if receipt.PrevHash != prevHash || receipt.ComputeHash() != receipt.Hash {
+			return fmt.Errorf("receipt %d: %w", receipt.ID, ErrErasureChainBroken)
+		}
+		prevHash = receipt.Hash
+		checked++
+		return nil
+	})
+	return checked, err
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"example/internal/model"
+)
+
+// fakeReceiptRepo holds the receipts in chain order
+type fakeReceiptRepo struct {
+	receipts []model.ErasureReceipt
+}
+
+func (r *fakeReceiptRepo) Each(fn func(receipt model.ErasureReceipt) error) error {
+	for _, receipt := range r.receipts {
+		if err := fn(receipt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// receiptChain builds a valid chain of n receipts, the way the repository
+// appends them
+func receiptChain(n int) []model.ErasureReceipt {
+	receipts := make([]model.ErasureReceipt, n)
+	prevHash := ""
+	for i := range receipts {
+		receipts[i] = model.ErasureReceipt{
+			ID:        uint(i + 1),
+			UserID:    uint(100 + i),
+			EmailHash: "hash",
+			ErasedAt:  time.Date(2024, 5, 1, 10, i, 0, 0, time.UTC),
+			PrevHash:  prevHash,
+		}
+		receipts[i].Hash = receipts[i].ComputeHash()
+		prevHash = receipts[i].Hash
+	}
+	return receipts
+}
+
+func TestVerifyErasureReceipts(t *testing.T) {
+	tests := []struct {
+		name        string
+		tamper      func(receipts []model.ErasureReceipt) []model.ErasureReceipt
+		wantChecked int
+		wantErr     bool
+	}{
+		{
+			name:        "empty chain",
+			tamper:      func(r []model.ErasureReceipt) []model.ErasureReceipt { return nil },
+			wantChecked: 0,
+		},
+		{
+			name:        "intact chain",
+			tamper:      func(r []model.ErasureReceipt) []model.ErasureReceipt { return r },
+			wantChecked: 3,
+		},
+		{
+			name: "edited receipt",
+			tamper: func(r []model.ErasureReceipt) []model.ErasureReceipt {
+				r[1].UserID = 1
+				return r
+			},
+			wantChecked: 1,
+			wantErr:     true,
+		},
+		{
+			name: "edited receipt with its hash recomputed",
+			tamper: func(r []model.ErasureReceipt) []model.ErasureReceipt {
+				r[1].UserID = 1
+				r[1].Hash = r[1].ComputeHash()
+				return r
+			},
+			wantChecked: 2,
+			wantErr:     true,
+		},
+		{
+			name:        "removed receipt",
+			tamper:      func(r []model.ErasureReceipt) []model.ErasureReceipt { return append(r[:1], r[2:]...) },
+			wantChecked: 1,
+			wantErr:     true,
+		},
+		{
+			name:        "removed first receipt",
+			tamper:      func(r []model.ErasureReceipt) []model.ErasureReceipt { return r[1:] },
+			wantChecked: 0,
+			wantErr:     true,
+		},
+		{
+			name:        "removed last receipt goes unnoticed",
+			tamper:      func(r []model.ErasureReceipt) []model.ErasureReceipt { return r[:2] },
+			wantChecked: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &privacyService{receiptRepo: &fakeReceiptRepo{receipts: tt.tamper(receiptChain(3))}}
+
+			checked, err := s.VerifyErasureReceipts()
+			if tt.wantErr != errors.Is(err, ErrErasureChainBroken) {
+				t.Errorf("VerifyErasureReceipts() error = %v, want broken chain %v", err, tt.wantErr)
+			}
+			if checked != tt.wantChecked {
+				t.Errorf("VerifyErasureReceipts() checked = %d, want %d", checked, tt.wantChecked)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"example/pkg/cache"
+	"example/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// rateLimitTimeout bounds the cache round trips of a rate limit check
+const rateLimitTimeout = 500 * time.Millisecond
+
+// ErrRateLimited is returned once a caller made more requests than allowed
+// within the window
+var ErrRateLimited = &TooManyRequestsError{Message: "too many requests, please try again later"}
+
+// RateLimiter counts events per key in fixed windows, such as requests per
+// client IP. Cache errors never block a request: they are logged and the
+// event is let through, so the API keeps working without Redis.
+type RateLimiter interface {
+	// Allow counts an event for the key and returns ErrRateLimited, carrying
+	// the time left in the window, once the key went over the limit
+	Allow(key string) error
+}
+
+type rateLimiter struct {
+	cacheManager cache.Manager
+	name         string
+	limit        int
+	window       time.Duration
+	logger       *zap.Logger
+}
+
+// NewRateLimiter returns a limiter allowing limit events per key within
+// window. The name keeps the counters of different limiters apart.
+func NewRateLimiter(cacheManager cache.Manager, name string, limit int, window time.Duration) RateLimiter {
+	return &rateLimiter{
+		cacheManager: cacheManager,
+		name:         name,
+		limit:        limit,
+		window:       window,
+		logger:       logger.GetLogger().With(zap.String("component", "rate-limiter"), zap.String("limiter", name)),
+	}
+}
+
+func (l *rateLimiter) Allow(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), rateLimitTimeout)
+	defer cancel()
+
+	cacheKey := l.cacheManager.Keys().Key("ratelimit", l.name, key)
+	count, err := l.cacheManager.Increment(ctx, cacheKey, 1, l.window)
+	if err != nil {
+		l.logger.Error("Failed to count request", zap.Error(err))
+		return nil
+	}
+	if count <= int64(l.limit) {
+		return nil
+	}
+
+	retryAfter, err := l.cacheManager.TTL(ctx, cacheKey)
+	if err != nil || retryAfter < 0 {
+		retryAfter = 0
+	}
+	return &TooManyRequestsError{Message: ErrRateLimited.Message, RetryAfter: retryAfter, Err: ErrRateLimited}
+}
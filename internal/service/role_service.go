@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/logger"
+	"example/pkg/validator"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidRole is returned when granting or revoking a role that doesn't
+	// exist or is implied for every user
+	ErrInvalidRole = &ValidationError{
+		Message: "role must be admin or support",
+		Fields:  []validator.ValidationError{{Field: "Role", Tag: "oneof", Value: "admin support"}},
+	}
+	// ErrRoleNotGranted is returned when revoking a role the user doesn't have
+	ErrRoleNotGranted = &NotFoundError{Message: "user does not have this role"}
+	// ErrRevokeOwnAdmin is returned when an admin tries to revoke their own
+	// admin role, which could leave the API without any admin
+	ErrRevokeOwnAdmin = &ConflictError{Message: "admins cannot revoke their own admin role"}
+)
+
+// RoleService defines the interface for role management operations
+type RoleService interface {
+	// Roles returns all roles of the user, including the implicit user role
+	Roles(userID uint) ([]model.Role, error)
+	// Grant gives the role to the user. The actor is the admin granting it,
+	// without a user when granted from the command line.
+	Grant(userID uint, role model.Role, actor model.AuditActor) ([]model.Role, error)
+	// Revoke takes the role from the user. The actor is the admin revoking
+	// it, without a user when revoked from the command line.
+	Revoke(userID uint, role model.Role, actor model.AuditActor) ([]model.Role, error)
+}
+
+type roleService struct {
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+	logger   *zap.Logger
+}
+
+func NewRoleService(roleRepo repository.RoleRepository, userRepo repository.UserRepository) RoleService {
+	return &roleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+		logger:   logger.GetLogger().With(zap.String("component", "role-service")),
+	}
+}
+
+func (s *roleService) Roles(userID uint) ([]model.Role, error) {
+	granted, err := s.roleRepo.GetRoles(userID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return append([]model.Role{model.RoleUser}, granted...), nil
+}
+
+func (s *roleService) Grant(userID uint, role model.Role, actor model.AuditActor) ([]model.Role, error) {
+	if !role.Valid() || role == model.RoleUser {
+		return nil, ErrInvalidRole
+	}
+	if err := s.checkUser(userID); err != nil {
+		return nil, err
+	}
+
+	err := s.roleRepo.Grant(&model.UserRole{
+		UserID:    userID,
+		Role:      role,
+		GrantedAt: time.Now(),
+		GrantedBy: actor.UserID,
+	}, actor)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	s.logger.Info("Role granted", zap.Uint("user_id", userID), zap.String("role", string(role)), zap.Uintp("granted_by", actor.UserID))
+	return s.Roles(userID)
+}
+
+func (s *roleService) Revoke(userID uint, role model.Role, actor model.AuditActor) ([]model.Role, error) {
+	if !role.Valid() || role == model.RoleUser {
+		return nil, ErrInvalidRole
+	}
+	if role == model.RoleAdmin && actor.UserID != nil && *actor.UserID == userID {
+		return nil, ErrRevokeOwnAdmin
+	}
+	if err := s.checkUser(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.roleRepo.Revoke(userID, role, actor); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotGranted
+		}
+		return nil, translateError(err)
+	}
+
+	s.logger.Info("Role revoked", zap.Uint("user_id", userID), zap.String("role", string(role)), zap.Uintp("revoked_by", actor.UserID))
+	return s.Roles(userID)
+}
+
+// checkUser makes sure the user exists, so granting a role to an unknown
+// user is reported as such rather than as a foreign key violation
+func (s *roleService) checkUser(userID uint) error {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return translateError(err)
+	}
+	return nil
+}
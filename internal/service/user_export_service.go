@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/logger"
+	"example/pkg/validator"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// exportBatchSize is how many users are loaded per page while exporting
+const exportBatchSize = 1000
+
+// Formats users can be exported in
+const (
+	UserExportFormatCSV    = "csv"
+	UserExportFormatNDJSON = "ndjson"
+	UserExportFormatJSON   = "json"
+)
+
+// userExportFields maps each exportable field to its value. Password hashes
+// are deliberately not exportable.
+var userExportFields = map[string]func(user *model.User) interface{}{
+	"id":                func(user *model.User) interface{} { return user.ID },
+	"name":              func(user *model.User) interface{} { return user.Name },
+	"email":             func(user *model.User) interface{} { return user.Email },
+	"email_verified_at": func(user *model.User) interface{} { return exportTime(user.EmailVerifiedAt) },
+	"avatar_url":        func(user *model.User) interface{} { return user.AvatarURL },
+	"status":            func(user *model.User) interface{} { return user.Status },
+	"version":           func(user *model.User) interface{} { return user.Version },
+	"created_at":        func(user *model.User) interface{} { return exportTime(&user.CreatedAt) },
+	"updated_at":        func(user *model.User) interface{} { return exportTime(&user.UpdatedAt) },
+}
+
+// UserExportFields are the fields exported when none are selected, in order
+var UserExportFields = []string{"id", "name", "email", "email_verified_at", "avatar_url", "status", "version", "created_at", "updated_at"}
+
+func exportTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.UTC().Format(time.RFC3339)
+	return &formatted
+}
+
+// UserExportOptions selects what an export contains
+type UserExportOptions struct {
+	Format string
+	// Fields are exported in the given order, defaulting to UserExportFields
+	Fields []string
+	Filter repository.UserListFilter
+}
+
+// UserExportService defines the interface for user exports
+type UserExportService interface {
+	// Validate checks the options, so errors can be reported before any
+	// output is written
+	Validate(opts UserExportOptions) error
+	// Export writes every active user matching the filter to w and returns
+	// how many were written
+	Export(ctx context.Context, w io.Writer, opts UserExportOptions) (int, error)
+}
+
+type userExportService struct {
+	repo   repository.UserRepository
+	logger *zap.Logger
+}
+
+func NewUserExportService(repo repository.UserRepository) UserExportService {
+	return &userExportService{
+		repo:   repo,
+		logger: logger.GetLogger().With(zap.String("component", "user-export-service")),
+	}
+}
+
+func (s *userExportService) Validate(opts UserExportOptions) error {
+	var fields []validator.ValidationError
+	switch opts.Format {
+	case UserExportFormatCSV, UserExportFormatNDJSON, UserExportFormatJSON:
+	default:
+		fields = append(fields, validator.ValidationError{Field: "Format", Tag: "oneof", Value: "csv ndjson json"})
+	}
+
+	seen := make(map[string]bool)
+	for _, field := range opts.Fields {
+		if _, ok := userExportFields[field]; !ok || seen[field] {
+			fields = append(fields, validator.ValidationError{Field: "Fields", Tag: "oneof", Value: strings.Join(UserExportFields, " ")})
+			break
+		}
+		seen[field] = true
+	}
+
+	if len(fields) > 0 {
+		return invalidFields(fields)
+	}
+	return nil
+}
+
+// Export walks the users page by page and flushes each page to w, so neither
+// side holds more than a page in memory
+func (s *userExportService) Export(ctx context.Context, w io.Writer, opts UserExportOptions) (int, error) {
+	if err := s.Validate(opts); err != nil {
+		return 0, err
+	}
+	if len(opts.Fields) == 0 {
+		opts.Fields = UserExportFields
+	}
+
+	s.logger.Info("Exporting users", zap.String("format", opts.Format), zap.Strings("fields", opts.Fields))
+
+	out := bufio.NewWriter(w)
+	encoder := newUserEncoder(out, opts.Format, opts.Fields)
+	if err := encoder.begin(); err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	err := s.repo.EachPage(opts.Filter, exportBatchSize, func(users []model.User) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for i := range users {
+			if err := encoder.write(&users[i]); err != nil {
+				return err
+			}
+		}
+		exported += len(users)
+		return flush(out, w)
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	if err := encoder.end(); err != nil {
+		return exported, err
+	}
+	if err := flush(out, w); err != nil {
+		return exported, err
+	}
+
+	s.logger.Info("Users exported", zap.Int("exported", exported))
+	return exported, nil
+}
+
+// flush pushes the buffered output to w, and on to the client when w is a
+// streaming HTTP response
+func flush(out *bufio.Writer, w io.Writer) error {
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// userEncoder writes users in one of the export formats
+type userEncoder interface {
+	begin() error
+	write(user *model.User) error
+	end() error
+}
+
+func newUserEncoder(w io.Writer, format string, fields []string) userEncoder {
+	switch format {
+	case UserExportFormatCSV:
+		return &csvUserEncoder{writer: csv.NewWriter(w), fields: fields}
+	case UserExportFormatJSON:
+		return &jsonUserEncoder{w: w, fields: fields, array: true}
+	default:
+		return &jsonUserEncoder{w: w, fields: fields}
+	}
+}
+
+type csvUserEncoder struct {
+	writer *csv.Writer
+	fields []string
+}
+
+func (e *csvUserEncoder) begin() error {
+	return e.writer.Write(e.fields)
+}
+
+func (e *csvUserEncoder) write(user *model.User) error {
+	record := make([]string, len(e.fields))
+	for i, field := range e.fields {
+		record[i] = csvValue(userExportFields[field](user))
+	}
+	if err := e.writer.Write(record); err != nil {
+		return err
+	}
+	// Hand the record to the underlying buffer, which is flushed per page
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvUserEncoder) end() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return csvText(*v)
+	case string:
+		return csvText(v)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// csvText keeps spreadsheets from running user supplied text as a formula by
+// prefixing the characters that start one with a quote
+func csvText(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// jsonUserEncoder writes one object per line for NDJSON, or the same objects
+// as the elements of an array for JSON. Objects are built by hand to keep the
+// selected field order.
+type jsonUserEncoder struct {
+	w       io.Writer
+	fields  []string
+	array   bool
+	written bool
+}
+
+func (e *jsonUserEncoder) begin() error {
+	if e.array {
+		_, err := io.WriteString(e.w, "[")
+		return err
+	}
+	return nil
+}
+
+func (e *jsonUserEncoder) write(user *model.User) error {
+	var sb strings.Builder
+	if e.array {
+		if e.written {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("{")
+	for i, field := range e.fields {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		key, _ := json.Marshal(field)
+		value, err := json.Marshal(userExportFields[field](user))
+		if err != nil {
+			return err
+		}
+		sb.Write(key)
+		sb.WriteString(":")
+		sb.Write(value)
+	}
+	sb.WriteString("}")
+	if !e.array {
+		sb.WriteString("\n")
+	}
+
+	e.written = true
+	_, err := io.WriteString(e.w, sb.String())
+	return err
+}
+
+func (e *jsonUserEncoder) end() error {
+	if e.array {
+		_, err := io.WriteString(e.w, "\n]\n")
+		return err
+	}
+	return nil
+}
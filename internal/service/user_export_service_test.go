@@ -0,0 +1,35 @@
+package service
+
+import "testing"
+
+func TestCSVValue(t *testing.T) {
+	name := "=cmd"
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "plain text", value: "John Doe", want: "John Doe"},
+		{name: "empty", value: "", want: ""},
+		{name: "formula", value: "=HYPERLINK(\"http://evil\")", want: "'=HYPERLINK(\"http://evil\")"},
+		{name: "plus", value: "+1+2", want: "'+1+2"},
+		{name: "minus", value: "-2+3", want: "'-2+3"},
+		{name: "at", value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{name: "tab", value: "\t=1", want: "'\t=1"},
+		{name: "carriage return", value: "\r=1", want: "'\r=1"},
+		{name: "formula character later on", value: "a=b", want: "a=b"},
+		{name: "string pointer", value: &name, want: "'=cmd"},
+		{name: "nil string pointer", value: (*string)(nil), want: ""},
+		{name: "id", value: uint(42), want: "42"},
+		{name: "negative number isn't text", value: -1, want: "-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvValue(tt.value); got != tt.want {
+				t.Errorf("csvValue(%#v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"example/internal/config"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/logger"
+	"example/pkg/validator"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultImportBatchSize = 500
+	defaultImportMaxRows   = 10000
+	defaultImportMaxBytes  = 32 << 20
+)
+
+// Statuses of an imported row
+const (
+	ImportRowCreated = "created"
+	// ImportRowValid is reported instead of created on a dry run
+	ImportRowValid  = "valid"
+	ImportRowFailed = "failed"
+)
+
+// UserImportRow is a row decoded from an import file
+type UserImportRow struct {
+	// Line is where the row starts in the file, for the report
+	Line int
+	User *model.User
+	// Fields lists the fields that failed the decoder's validation
+	Fields []validator.ValidationError
+	// Err is set when the row couldn't be decoded at all
+	Err error
+}
+
+// UserImportSource yields the rows of an import file, returning io.EOF after
+// the last one
+type UserImportSource interface {
+	Next() (*UserImportRow, error)
+}
+
+// UserImportRowResult is the outcome of importing one row
+type UserImportRowResult struct {
+	Line   int                         `json:"line"`
+	Email  string                      `json:"email,omitempty"`
+	Status string                      `json:"status"`
+	UserID uint                        `json:"user_id,omitempty"`
+	Error  string                      `json:"error,omitempty"`
+	Fields []validator.ValidationError `json:"fields,omitempty"`
+}
+
+// UserImportReport is the outcome of an import, with a result for every row
+// in file order
+type UserImportReport struct {
+	DryRun  bool `json:"dry_run"`
+	Total   int  `json:"total"`
+	Created int  `json:"created"`
+	Valid   int  `json:"valid"`
+	Failed  int  `json:"failed"`
+	// Truncated is set when the file had more rows than an import accepts;
+	// the rows after the limit weren't read
+	Truncated bool `json:"truncated"`
+	// Error is set when the import stopped part way, after some users were
+	// created. Rows missing from the report weren't imported.
+	Error string                `json:"error,omitempty"`
+	Rows  []UserImportRowResult `json:"rows"`
+}
+
+// UserImportService defines the interface for bulk user imports
+type UserImportService interface {
+	// Import creates a user for every valid row. With dryRun set, rows are
+	// checked exactly the same way but nothing is saved. An error stopping the
+	// import once users were created is reported in the report rather than
+	// returned, so the caller learns which rows were saved.
+	Import(ctx context.Context, source UserImportSource, dryRun bool, actor model.AuditActor) (*UserImportReport, error)
+	// MaxBytes is the largest import file accepted
+	MaxBytes() int64
+}
+
+type userImportService struct {
+	repo            repository.UserRepository
+	passwordPolicy  PasswordPolicy
+	emailNormalizer EmailNormalizer
+	workers         int
+	batchSize       int
+	maxRows         int
+	maxBytes        int64
+	logger          *zap.Logger
+}
+
+func NewUserImportService(
+	repo repository.UserRepository,
+	passwordPolicy PasswordPolicy,
+	emailNormalizer EmailNormalizer,
+	cfg *config.UsersConfig,
+) UserImportService {
+	workers := cfg.ImportWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	batchSize := cfg.ImportBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+	maxRows := cfg.ImportMaxRows
+	if maxRows <= 0 {
+		maxRows = defaultImportMaxRows
+	}
+	maxBytes := cfg.ImportMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultImportMaxBytes
+	}
+
+	return &userImportService{
+		repo:            repo,
+		passwordPolicy:  passwordPolicy,
+		emailNormalizer: emailNormalizer,
+		workers:         workers,
+		batchSize:       batchSize,
+		maxRows:         maxRows,
+		maxBytes:        maxBytes,
+		logger:          logger.GetLogger().With(zap.String("component", "user-import-service")),
+	}
+}
+
+func (s *userImportService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// importJob is a row on its way through the import pipeline
+type importJob struct {
+	user   *model.User
+	result UserImportRowResult
+}
+
+func (j *importJob) fail(message string, fields []validator.ValidationError) {
+	j.result.Status = ImportRowFailed
+	j.result.Error = message
+	j.result.Fields = fields
+}
+
+// Import streams the rows through three stages: they are read and validated
+// one by one, their passwords hashed by a bounded pool of workers, since
+// bcrypt dominates the cost, and the hashed users inserted in batches.
+func (s *userImportService) Import(ctx context.Context, source UserImportSource, dryRun bool, actor model.AuditActor) (*UserImportReport, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	report := &UserImportReport{DryRun: dryRun}
+	s.logger.Info("Importing users", zap.Bool("dry_run", dryRun), zap.Int("workers", s.workers))
+
+	jobs := make(chan *importJob, s.workers)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		readErr <- s.read(ctx, source, jobs, report)
+	}()
+
+	hashed := make(chan *importJob, s.workers)
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				s.hash(job)
+				select {
+				case hashed <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(hashed)
+	}()
+
+	var batch []*importJob
+	var err error
+	readDone := false
+	for job := range hashed {
+		if job.result.Status == ImportRowFailed {
+			report.add(job.result)
+			continue
+		}
+		batch = append(batch, job)
+		if len(batch) == s.batchSize {
+			if err = s.save(batch, dryRun, actor, report); err != nil {
+				break
+			}
+			batch = nil
+		}
+	}
+	if err == nil {
+		readDone = true
+		if err = <-readErr; err == nil {
+			err = s.save(batch, dryRun, actor, report)
+		}
+	}
+	if err != nil {
+		// The reader writes to the report too, wait until it stopped
+		cancel()
+		if !readDone {
+			<-readErr
+		}
+		return s.stopped(report, batch, err)
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].Line < report.Rows[j].Line })
+	s.logger.Info("Users imported",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("total", report.Total),
+		zap.Int("created", report.Created),
+		zap.Int("failed", report.Failed),
+	)
+	return report, nil
+}
+
+// stopped ends an import that failed part way. Before any user was created
+// the error is returned as is. Otherwise it is reported in the report, and
+// the rows of the batch that failed are marked failed, so the caller knows
+// which rows were created and can retry the others.
+func (s *userImportService) stopped(report *UserImportReport, batch []*importJob, err error) (*UserImportReport, error) {
+	if report.Created == 0 {
+		return nil, err
+	}
+	s.logger.Error("Import stopped part way", zap.Int("created", report.Created), zap.Error(err))
+
+	report.Error = "import stopped early, rows missing from the report were not imported"
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		report.Error = validationErr.Message
+	}
+	for _, job := range batch {
+		if job.result.Status == "" {
+			job.fail("not imported, the import stopped early", nil)
+			report.add(job.result)
+		}
+	}
+
+	sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].Line < report.Rows[j].Line })
+	return report, nil
+}
+
+// read decodes and validates the rows, applying the rules of CreateUser, and
+// hands them to the workers. Rows that fail are passed along as failed so the
+// report keeps a single writer.
+func (s *userImportService) read(ctx context.Context, source UserImportSource, jobs chan<- *importJob, report *UserImportReport) error {
+	seen := make(map[string]int)
+	rows := 0
+
+	for {
+		row, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return &ValidationError{Message: fmt.Sprintf("cannot read import file: %v", err), Err: err}
+		}
+		if rows == s.maxRows {
+			report.Truncated = true
+			return nil
+		}
+		rows++
+
+		job := &importJob{user: row.User, result: UserImportRowResult{Line: row.Line}}
+		switch {
+		case row.Err != nil:
+			job.fail(row.Err.Error(), nil)
+		case len(row.Fields) > 0:
+			job.result.Email = row.User.Email
+			job.fail("validation failed", row.Fields)
+		default:
+			row.User.Email = s.emailNormalizer.Normalize(row.User.Email)
+			job.result.Email = row.User.Email
+
+			fields := validator.ValidateStruct(row.User)
+			fields = append(fields, s.passwordPolicy.Validate("Password", row.User.Password)...)
+			key := strings.ToLower(row.User.Email)
+			if len(fields) > 0 {
+				job.fail("validation failed", fields)
+			} else if line, ok := seen[key]; ok {
+				job.fail(fmt.Sprintf("email is already used on line %d", line), nil)
+			} else {
+				seen[key] = row.Line
+			}
+		}
+
+		select {
+		case jobs <- job:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *userImportService) hash(job *importJob) {
+	if job.result.Status == ImportRowFailed {
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(job.user.Password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		job.fail("validation failed", []validator.ValidationError{{Field: "Password", Tag: "max", Value: "72"}})
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to hash imported password", zap.Int("line", job.result.Line), zap.Error(err))
+		job.fail("password could not be hashed", nil)
+		return
+	}
+	job.user.Password = string(hashedPassword)
+}
+
+// save inserts a batch of validated users. Emails already taken are reported
+// per row up front; if another request takes one in the meantime the batch
+// is retried row by row, so only that row fails.
+func (s *userImportService) save(batch []*importJob, dryRun bool, actor model.AuditActor, report *UserImportReport) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	emails := make([]string, len(batch))
+	for i, job := range batch {
+		emails[i] = job.user.Email
+	}
+	existing, err := s.repo.ExistingEmails(emails)
+	if err != nil {
+		return translateError(err)
+	}
+
+	var pending []*importJob
+	for _, job := range batch {
+		if existing[strings.ToLower(job.user.Email)] {
+			job.fail(ErrEmailTaken.Message, nil)
+			report.add(job.result)
+			continue
+		}
+		pending = append(pending, job)
+	}
+
+	if dryRun {
+		for _, job := range pending {
+			job.result.Status = ImportRowValid
+			report.add(job.result)
+		}
+		return nil
+	}
+
+	users := make([]*model.User, len(pending))
+	for i, job := range pending {
+		users[i] = job.user
+	}
+	err = s.repo.CreateBatch(users, actor)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return s.saveEach(pending, actor, report)
+	}
+	if err != nil {
+		return translateError(err)
+	}
+
+	for _, job := range pending {
+		job.created(report)
+	}
+	return nil
+}
+
+func (s *userImportService) saveEach(jobs []*importJob, actor model.AuditActor, report *UserImportReport) error {
+	for _, job := range jobs {
+		job.user.ID = 0
+		if err := s.repo.Create(job.user, actor); err != nil {
+			if !errors.Is(err, gorm.ErrDuplicatedKey) {
+				return translateError(err)
+			}
+			job.fail(ErrEmailTaken.Message, nil)
+			report.add(job.result)
+			continue
+		}
+		job.created(report)
+	}
+	return nil
+}
+
+func (j *importJob) created(report *UserImportReport) {
+	j.result.Status = ImportRowCreated
+	j.result.UserID = j.user.ID
+	report.add(j.result)
+}
+
+func (r *UserImportReport) add(result UserImportRowResult) {
+	r.Total++
+	switch result.Status {
+	case ImportRowCreated:
+		r.Created++
+	case ImportRowValid:
+		r.Valid++
+	case ImportRowFailed:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, result)
+}
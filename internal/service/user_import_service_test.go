@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"example/internal/config"
+	"example/internal/model"
+	"example/internal/repository"
+	"example/pkg/validator"
+)
+
+// fakeImportSource yields the rows, then err, or io.EOF when err is nil
+type fakeImportSource struct {
+	rows []*UserImportRow
+	err  error
+}
+
+func (s *fakeImportSource) Next() (*UserImportRow, error) {
+	if len(s.rows) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	row := s.rows[0]
+	s.rows = s.rows[1:]
+	return row, nil
+}
+
+// fakeImportRepo knows the taken emails and fails the CreateBatch calls with
+// the queued errors. Other methods aren't used by imports.
+type fakeImportRepo struct {
+	repository.UserRepository
+
+	mu        sync.Mutex
+	taken     map[string]bool
+	batchErrs []error
+	nextID    uint
+}
+
+func (r *fakeImportRepo) ExistingEmails(emails []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	for _, email := range emails {
+		if r.taken[strings.ToLower(email)] {
+			existing[strings.ToLower(email)] = true
+		}
+	}
+	return existing, nil
+}
+
+func (r *fakeImportRepo) CreateBatch(users []*model.User, actor model.AuditActor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.batchErrs) > 0 {
+		err := r.batchErrs[0]
+		r.batchErrs = r.batchErrs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	for _, user := range users {
+		r.nextID++
+		user.ID = r.nextID
+	}
+	return nil
+}
+
+func importRow(line int, email string) *UserImportRow {
+	return &UserImportRow{Line: line, User: &model.User{Name: "User", Email: email, Password: "Passw0rd"}}
+}
+
+// newTestUserImportService hashes with a single worker, so rows reach the
+// batches in file order
+func newTestUserImportService(repo repository.UserRepository, batchSize int) *userImportService {
+	return NewUserImportService(
+		repo,
+		NewPasswordPolicy(&config.AuthConfig{}),
+		EmailNormalizer{},
+		&config.UsersConfig{ImportWorkers: 1, ImportBatchSize: batchSize},
+	).(*userImportService)
+}
+
+func TestUserImportRows(t *testing.T) {
+	repo := &fakeImportRepo{taken: map[string]bool{"taken@example.com": true}}
+	s := newTestUserImportService(repo, 10)
+	source := &fakeImportSource{rows: []*UserImportRow{
+		importRow(2, " john@Example.com"),
+		importRow(3, "John@example.COM"),
+		importRow(4, "Taken@example.com"),
+		{Line: 5, Err: errors.New("wrong number of fields")},
+		{Line: 6, User: &model.User{Email: "bad"}, Fields: []validator.ValidationError{{Field: "Email", Tag: "email"}}},
+		importRow(7, "jane@example.com"),
+	}}
+
+	report, err := s.Import(context.Background(), source, false, model.AuditActor{})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	want := []struct {
+		status string
+		email  string
+		error  string
+	}{
+		{status: ImportRowCreated, email: "john@example.com"},
+		{status: ImportRowFailed, email: "John@example.com", error: "email is already used on line 2"},
+		{status: ImportRowFailed, email: "Taken@example.com", error: ErrEmailTaken.Message},
+		{status: ImportRowFailed, error: "wrong number of fields"},
+		{status: ImportRowFailed, email: "bad", error: "validation failed"},
+		{status: ImportRowCreated, email: "jane@example.com"},
+	}
+	if len(report.Rows) != len(want) {
+		t.Fatalf("Import() reported %d rows, want %d", len(report.Rows), len(want))
+	}
+	for i, row := range report.Rows {
+		if row.Line != i+2 || row.Status != want[i].status || row.Email != want[i].email || row.Error != want[i].error {
+			t.Errorf("row %d = %+v, want %+v", i, row, want[i])
+		}
+	}
+	if report.Total != 6 || report.Created != 2 || report.Failed != 4 {
+		t.Errorf("Import() total = %d, created = %d, failed = %d, want 6, 2, 4", report.Total, report.Created, report.Failed)
+	}
+}
+
+func TestUserImportStopped(t *testing.T) {
+	errBackend := errors.New("connection reset")
+	errRead := errors.New("unexpected EOF")
+
+	tests := []struct {
+		name      string
+		rows      int
+		readErr   error
+		batchErrs []error
+		// wantErr is set when nothing was created and Import fails
+		wantErr     bool
+		wantCreated int
+		wantError   string
+		wantFailed  []int
+	}{
+		{
+			name:      "first batch fails",
+			rows:      4,
+			batchErrs: []error{errBackend},
+			wantErr:   true,
+		},
+		{
+			name:        "later batch fails",
+			rows:        5,
+			batchErrs:   []error{nil, errBackend},
+			wantCreated: 2,
+			wantError:   "import stopped early, rows missing from the report were not imported",
+			wantFailed:  []int{4, 5},
+		},
+		{
+			name:    "read fails before any batch",
+			rows:    1,
+			readErr: errRead,
+			wantErr: true,
+		},
+		{
+			name:        "read fails after a batch",
+			rows:        2,
+			readErr:     errRead,
+			wantCreated: 2,
+			wantError:   "cannot read import file: unexpected EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeImportSource{err: tt.readErr}
+			for i := 0; i < tt.rows; i++ {
+				source.rows = append(source.rows, importRow(i+2, strings.Repeat("a", i+1)+"@example.com"))
+			}
+			s := newTestUserImportService(&fakeImportRepo{batchErrs: tt.batchErrs}, 2)
+
+			report, err := s.Import(context.Background(), source, false, model.AuditActor{})
+			if tt.wantErr {
+				if err == nil || report != nil {
+					t.Fatalf("Import() = %+v, %v, want an error", report, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+
+			if report.Created != tt.wantCreated || report.Error != tt.wantError {
+				t.Errorf("Import() created = %d, error = %q, want %d, %q", report.Created, report.Error, tt.wantCreated, tt.wantError)
+			}
+			var failed []int
+			for i, row := range report.Rows {
+				if i > 0 && row.Line < report.Rows[i-1].Line {
+					t.Errorf("rows not in file order: line %d after %d", row.Line, report.Rows[i-1].Line)
+				}
+				if row.Status == ImportRowFailed {
+					failed = append(failed, row.Line)
+				}
+			}
+			if len(failed) != len(tt.wantFailed) {
+				t.Fatalf("failed lines = %v, want %v", failed, tt.wantFailed)
+			}
+			for i := range failed {
+				if failed[i] != tt.wantFailed[i] {
+					t.Errorf("failed lines = %v, want %v", failed, tt.wantFailed)
+				}
+			}
+		})
+	}
+}
@@ -1,56 +1,459 @@
 package service
 
 import (
+	"errors"
 	"example/internal/model"
 	"example/internal/repository"
 	"example/pkg/validator"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// dummyPasswordHash is checked against on logins with an unknown email, so
+// they take as long as logins with a wrong password and don't tell which
+// emails have an account. No password matches it.
+const dummyPasswordHash = "$2a$10$6cePZfzepUNn0bjUfHGsYucE.N8SN8NZrjLUfDfz5nK32FdIn/T9C"
+
+var (
+	// ErrInvalidStatus is returned when setting a status that doesn't exist
+	ErrInvalidStatus = &ValidationError{
+		Message: "status must be active, suspended or disabled",
+		Fields:  []validator.ValidationError{{Field: "Status", Tag: "oneof", Value: "active suspended disabled"}},
+	}
+	// ErrStatusReasonRequired is returned when suspending or disabling a user
+	// without saying why
+	ErrStatusReasonRequired = &ValidationError{
+		Message: "a reason is required to suspend or disable a user",
+		Fields:  []validator.ValidationError{{Field: "Reason", Tag: "required"}},
+	}
+	// ErrStatusUnchanged is returned when the user already has the status
+	ErrStatusUnchanged = &ConflictError{Message: "user already has this status"}
+	// ErrChangeOwnStatus is returned when an admin tries to suspend or
+	// disable themselves, which would lock them out
+	ErrChangeOwnStatus = &ConflictError{Message: "admins cannot change their own status"}
+	// ErrStaffStatus is returned when a caller who can't manage roles tries
+	// to change the status of an admin or support user
+	ErrStaffStatus = &ForbiddenError{Message: "only admins can change the status of admins and support staff"}
+)
+
+// UserUpdate holds the fields of a partial user update. Nil fields are left
+// unchanged.
+type UserUpdate struct {
+	Name  *string
+	Email *string
+}
+
 // UserService defines the interface for user service operations
 type UserService interface {
-	CreateUser(user *model.User) ([]validator.ValidationError, error)
+	CreateUser(user *model.User, actor model.AuditActor) error
 	GetUser(id uint) (*model.User, error)
+	ListUsers(query repository.UserListQuery) (*repository.UserPage, error)
+	SearchUsers(scope repository.UserScope, query string, limit int) ([]repository.UserSearchResult, error)
+	UpdateUser(id uint, update UserUpdate, version uint, actor model.AuditActor) (*model.User, error)
+	DeleteUser(id uint, actor model.AuditActor) error
+	RestoreUser(id uint, actor model.AuditActor) (*model.User, error)
+	PurgeDeletedUsers(retention time.Duration) (int64, error)
 	GetUserByEmail(email string) (*model.User, error)
 	Login(email, password string) (*model.User, error)
+	// RecordLogin stores that the user just logged in
+	RecordLogin(id uint) error
+	ChangePassword(id uint, currentPassword, newPassword string, actor model.AuditActor) error
+	// Status returns the status of the active user with the ID. It is served
+	// from a short-lived cache, as it is checked on every request.
+	Status(id uint) (model.UserStatus, error)
+	// SetStatus suspends, disables or reactivates the user. A reason is
+	// required unless reactivating. The actor is the admin changing it,
+	// without a user when changed from the command line. Only callers who
+	// can manage roles may change the status of admins and support staff.
+	SetStatus(id uint, status model.UserStatus, reason string, actor model.AuditActor) (*model.User, error)
+	EmailCollisions() ([]EmailCollision, error)
+	// NormalizeEmails rewrites the stored emails that aren't in the form the
+	// EmailNormalizer gives, which they must be in for logins and lookups to
+	// find them, e.g. after enabling the provider rules
+	NormalizeEmails(actor model.AuditActor) (*EmailNormalization, error)
+}
+
+// EmailCollision is a group of active users whose emails normalize to the
+// same identity
+type EmailCollision struct {
+	Key   string
+	Users []model.User
+}
+
+// EmailNormalization is the outcome of NormalizeEmails
+type EmailNormalization struct {
+	Normalized int
+	// Collisions are left unchanged, they have to be resolved first
+	Collisions []EmailCollision
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo            repository.UserRepository
+	roleRepo        repository.RoleRepository
+	passwordPolicy  PasswordPolicy
+	emailNormalizer EmailNormalizer
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
+func NewUserService(repo repository.UserRepository, roleRepo repository.RoleRepository, passwordPolicy PasswordPolicy, emailNormalizer EmailNormalizer) UserService {
 	return &userService{
-		repo: repo,
+		repo:            repo,
+		roleRepo:        roleRepo,
+		passwordPolicy:  passwordPolicy,
+		emailNormalizer: emailNormalizer,
 	}
 }
 
-func (s *userService) CreateUser(user *model.User) ([]validator.ValidationError, error) {
-	errors := validator.ValidateStruct(user)
-	if len(errors) > 0 {
-		return errors, nil
+func (s *userService) CreateUser(user *model.User, actor model.AuditActor) error {
+	user.Email = s.emailNormalizer.Normalize(user.Email)
+
+	validationErrs := validator.ValidateStruct(user)
+	validationErrs = append(validationErrs, s.passwordPolicy.Validate("Password", user.Password)...)
+	if len(validationErrs) > 0 {
+		return invalidFields(validationErrs)
 	}
 
 	// Hash the password before saving
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	user.Password = string(hashedPassword)
 
-	err = s.repo.Create(user)
-	return nil, err
+	if err := s.repo.Create(user, actor); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrEmailTaken
+		}
+		return translateError(err)
+	}
+
+	return nil
 }
 
 func (s *userService) GetUser(id uint) (*model.User, error) {
-	return s.repo.GetByID(id)
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, translateError(err)
+	}
+
+	return user, nil
+}
+
+func (s *userService) ListUsers(query repository.UserListQuery) (*repository.UserPage, error) {
+	page, err := s.repo.List(query)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, ErrInvalidCursor
+		}
+		return nil, translateError(err)
+	}
+
+	return page, nil
+}
+
+func (s *userService) SearchUsers(scope repository.UserScope, query string, limit int) ([]repository.UserSearchResult, error) {
+	results, err := s.repo.Search(scope, query, limit)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return results, nil
+}
+
+// UpdateUser applies a partial update to the user, provided it is still at the
+// given version. It returns ErrVersionConflict if someone else updated the
+// user in the meantime.
+func (s *userService) UpdateUser(id uint, update UserUpdate, version uint, actor model.AuditActor) (*model.User, error) {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, translateError(err)
+	}
+
+	if user.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	if update.Name != nil {
+		user.Name = *update.Name
+	}
+
+	if update.Email != nil && s.emailNormalizer.Normalize(*update.Email) != user.Email {
+		email := s.emailNormalizer.Normalize(*update.Email)
+		existing, err := s.repo.GetByEmail(email)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		// Changing only the case of the user's own email is fine
+		if existing != nil && existing.ID != user.ID {
+			return nil, ErrEmailTaken
+		}
+		// A new address has to be confirmed again, the same one with a
+		// different case reaches the same mailbox
+		if !strings.EqualFold(s.emailNormalizer.Normalize(user.Email), email) {
+			user.EmailVerifiedAt = nil
+		}
+		user.Email = email
+	}
+
+	if err := s.repo.Update(user, version, actor); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, ErrVersionConflict
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrEmailTaken
+		}
+		return nil, translateError(err)
+	}
+
+	return user, nil
+}
+
+func (s *userService) DeleteUser(id uint, actor model.AuditActor) error {
+	if err := s.repo.Delete(id, actor); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return ErrUserNotFound
+		case errors.Is(err, repository.ErrLastOwner):
+			return ErrLastOwner
+		}
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// RestoreUser undoes a soft delete. It fails with ErrEmailTaken if the email
+// was registered again in the meantime.
+func (s *userService) RestoreUser(id uint, actor model.AuditActor) (*model.User, error) {
+	user, err := s.repo.Restore(id, actor)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrEmailTaken
+		}
+		return nil, translateError(err)
+	}
+
+	return user, nil
+}
+
+func (s *userService) Status(id uint) (model.UserStatus, error) {
+	status, err := s.repo.GetStatus(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrUserNotFound
+		}
+		return "", translateError(err)
+	}
+
+	return status, nil
 }
 
+func (s *userService) SetStatus(id uint, status model.UserStatus, reason string, actor model.AuditActor) (*model.User, error) {
+	if !status.Valid() {
+		return nil, ErrInvalidStatus
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" && status != model.UserStatusActive {
+		return nil, ErrStatusReasonRequired
+	}
+	if actor.UserID != nil && *actor.UserID == id {
+		return nil, ErrChangeOwnStatus
+	}
+
+	user, err := s.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkStaffStatus(id, actor); err != nil {
+		return nil, err
+	}
+	if user.Status == status || (status == model.UserStatusActive && user.Active()) {
+		return nil, ErrStatusUnchanged
+	}
+
+	var reasonPtr *string
+	if reason != "" {
+		reasonPtr = &reason
+	}
+	if _, err := s.repo.UpdateStatus(id, status, reasonPtr, time.Now(), actor); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, translateError(err)
+	}
+
+	return s.GetUser(id)
+}
+
+// checkStaffStatus keeps support from suspending admins or each other: the
+// status of a staff user may only be changed by a caller who could take
+// their roles away anyway. The command line is trusted.
+func (s *userService) checkStaffStatus(id uint, actor model.AuditActor) error {
+	if actor.UserID == nil {
+		return nil
+	}
+
+	targetRoles, err := s.roleRepo.GetRoles(id)
+	if err != nil {
+		return translateError(err)
+	}
+	if !model.HasRole(targetRoles, model.RoleAdmin, model.RoleSupport) {
+		return nil
+	}
+
+	actorRoles, err := s.roleRepo.GetRoles(*actor.UserID)
+	if err != nil {
+		return translateError(err)
+	}
+	if !model.HasPermission(actorRoles, model.PermissionRolesManage) {
+		return ErrStaffStatus
+	}
+	return nil
+}
+
+// PurgeDeletedUsers permanently removes users that were soft deleted more
+// than retention ago
+func (s *userService) PurgeDeletedUsers(retention time.Duration) (int64, error) {
+	purged, err := s.repo.PurgeDeleted(time.Now().Add(-retention))
+	return purged, translateError(err)
+}
+
+// GetUserByEmail returns the user with the given email, or nil if there is
+// none
 func (s *userService) GetUserByEmail(email string) (*model.User, error) {
-	return s.repo.GetByEmail(email)
+	user, err := s.repo.GetByEmail(s.emailNormalizer.Normalize(email))
+	return user, translateError(err)
+}
+
+// EmailCollisions finds active users that share an email identity, which
+// have to be merged or renamed before the case-insensitive unique index can
+// be created, or that would collide under the provider rules
+func (s *userService) EmailCollisions() ([]EmailCollision, error) {
+	groups, err := s.emailGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	var collisions []EmailCollision
+	for _, group := range groups {
+		if len(group.Users) > 1 {
+			collisions = append(collisions, group)
+		}
+	}
+
+	return collisions, nil
 }
 
+// NormalizeEmails only touches users whose identity is theirs alone, so it
+// never makes two active users share an email
+func (s *userService) NormalizeEmails(actor model.AuditActor) (*EmailNormalization, error) {
+	groups, err := s.emailGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EmailNormalization{}
+	for _, group := range groups {
+		if len(group.Users) > 1 {
+			result.Collisions = append(result.Collisions, group)
+			continue
+		}
+
+		user := group.Users[0]
+		email := s.emailNormalizer.Normalize(user.Email)
+		if email == user.Email {
+			continue
+		}
+		err := s.repo.UpdateEmail(user.ID, user.Email, email, actor)
+		switch {
+		case err == nil:
+			result.Normalized++
+		case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, gorm.ErrDuplicatedKey):
+			// The user changed or another one took the email meanwhile
+		default:
+			return result, translateError(err)
+		}
+	}
+
+	return result, nil
+}
+
+// emailGroups groups the active users by email identity, in the order the
+// identities were first seen
+func (s *userService) emailGroups() ([]EmailCollision, error) {
+	index := make(map[string]int)
+	var groups []EmailCollision
+
+	err := s.repo.EachEmail(func(user model.User) {
+		key := s.emailNormalizer.Key(user.Email)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, EmailCollision{Key: key})
+		}
+		groups[i].Users = append(groups[i].Users, user)
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return groups, nil
+}
+
+// ChangePassword replaces the user's password after checking the current one.
+// Every token issued before the change stops being accepted.
+func (s *userService) ChangePassword(id uint, currentPassword, newPassword string, actor model.AuditActor) error {
+	user, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return translateError(err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return ErrInvalidPassword
+	}
+
+	validationErrs := s.passwordPolicy.Validate("NewPassword", newPassword)
+	if newPassword == currentPassword {
+		validationErrs = append(validationErrs, validator.ValidationError{Field: "NewPassword", Tag: "nefield", Value: "CurrentPassword"})
+	}
+	if len(validationErrs) > 0 {
+		return invalidFields(validationErrs)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	// Token claims only carry whole seconds
+	changedAt := time.Now().Truncate(time.Second)
+	if err := s.repo.UpdatePassword(id, string(hashedPassword), changedAt, actor); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// Login returns the user with the given credentials, or ErrInvalidCredentials
+// if the email is unknown or the password doesn't match
 func (s *userService) Login(email, password string) (*model.User, error) {
 	user, err := s.GetUserByEmail(email)
 	if err != nil {
@@ -58,13 +461,18 @@ func (s *userService) Login(email, password string) (*model.User, error) {
 	}
 
 	if user == nil {
-		return nil, nil
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		return nil, ErrInvalidCredentials
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return nil, nil
+		return nil, ErrInvalidCredentials
 	}
 
 	return user, nil
 }
+
+func (s *userService) RecordLogin(id uint) error {
+	return translateError(s.repo.RecordLogin(id, time.Now()))
+}
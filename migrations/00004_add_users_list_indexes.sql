@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Support keyset pagination over active users for every sort order
+CREATE INDEX users_created_at_id_idx ON users (created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX users_name_id_idx ON users (name, id) WHERE deleted_at IS NULL;
+CREATE INDEX users_email_id_idx ON users (email, id) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_email_id_idx;
+DROP INDEX IF EXISTS users_name_id_idx;
+DROP INDEX IF EXISTS users_created_at_id_idx;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Emails are unique regardless of case. Creating the index fails if active
+-- users still share an email in different cases; list them with
+-- `admin users email-collisions` and resolve them first.
+CREATE UNIQUE INDEX users_email_lower_active_key ON users (lower(email)) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS users_email_active_key;
+
+-- Domains are case-insensitive, store them lowercased. The domain starts
+-- after the last @, as a quoted local part may contain one too.
+UPDATE users
+SET email = left(email, length(email) - position('@' IN reverse(email))) ||
+    lower(right(email, position('@' IN reverse(email))))
+WHERE position('@' IN email) > 0
+    AND right(email, position('@' IN reverse(email))) <> lower(right(email, position('@' IN reverse(email))));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE UNIQUE INDEX users_email_active_key ON users (email) WHERE deleted_at IS NULL;
+DROP INDEX IF EXISTS users_email_lower_active_key;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every user implicitly has the user role, only the others are stored
+CREATE TABLE user_roles (
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role VARCHAR(32) NOT NULL CHECK (role IN ('admin', 'support')),
+    granted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    granted_by INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    PRIMARY KEY (user_id, role)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_roles;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Erased users are anonymized in place and soft deleted; erased_at keeps them
+-- from being restored
+ALTER TABLE users ADD COLUMN erased_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- Receipts outlive the users they describe, so user_id is not a foreign key.
+-- Each hash covers the previous one, see model.ErasureReceipt.
+CREATE TABLE erasure_receipts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    email_hash VARCHAR(64) NOT NULL,
+    requested_by INTEGER DEFAULT NULL,
+    erased_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL UNIQUE
+);
+
+CREATE INDEX erasure_receipts_user_id_idx ON erasure_receipts (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS erasure_receipts;
+ALTER TABLE users DROP COLUMN IF EXISTS erased_at;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active'
+    CONSTRAINT users_status_check CHECK (status IN ('active', 'suspended', 'disabled'));
+ALTER TABLE users ADD COLUMN status_reason TEXT DEFAULT NULL;
+ALTER TABLE users ADD COLUMN status_changed_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- Almost every user is active, so only the others are worth indexing
+CREATE INDEX users_status_idx ON users (status) WHERE status <> 'active';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_status_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS status_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Invitations are addressed to an email, whether or not it has an account yet,
+-- and become a membership once accepted
+CREATE TABLE organization_invitations (
+    organization_id INTEGER NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(32) NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    invited_by INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX organization_invitations_email_key ON organization_invitations (organization_id, lower(email));
+CREATE INDEX organization_invitations_lower_email_idx ON organization_invitations (lower(email));
+
+INSERT INTO organization_invitations (organization_id, email, role, invited_by, created_at)
+SELECT m.organization_id, u.email, m.role, m.invited_by, m.created_at
+FROM memberships m
+JOIN users u ON u.id = m.user_id
+WHERE m.accepted_at IS NULL;
+
+DELETE FROM memberships WHERE accepted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+INSERT INTO memberships (organization_id, user_id, role, invited_by, created_at)
+SELECT i.organization_id, u.id, i.role, i.invited_by, i.created_at
+FROM organization_invitations i
+JOIN users u ON lower(u.email) = lower(i.email) AND u.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+DROP TABLE IF EXISTS organization_invitations;
+-- +goose StatementEnd
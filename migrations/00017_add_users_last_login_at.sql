@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+
+-- Cache warmup picks the users who logged in last
+CREATE INDEX users_last_login_at_idx ON users (last_login_at DESC NULLS LAST) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_last_login_at_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
+-- +goose StatementEnd
@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"example/pkg/logger"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBreakerThreshold     = 5
+	defaultBreakerProbeInterval = 10 * time.Second
+)
+
+// ErrUnavailable is returned by every cache operation while the circuit
+// breaker is open. Callers should treat it like a cache miss.
+var ErrUnavailable = errors.New("cache unavailable")
+
+// State describes whether the circuit breaker lets cache calls through
+type State string
+
+const (
+	// StateClosed means the cache is healthy and used normally
+	StateClosed State = "closed"
+	// StateOpen means the cache is bypassed until a probe succeeds
+	StateOpen State = "open"
+)
+
+// StateReporter exposes the cache state, e.g. for health checks
+type StateReporter interface {
+	State() State
+}
+
+// BreakerOptions configure a CircuitBreaker. Zero values fall back to the
+// defaults.
+type BreakerOptions struct {
+	// Threshold is the number of consecutive failures that opens the breaker
+	Threshold int
+	// ProbeInterval is how often the backend is pinged while open
+	ProbeInterval time.Duration
+}
+
+// CircuitBreaker wraps a Manager and stops calling it after a number of
+// consecutive backend failures. While open, every operation fails fast with
+// ErrUnavailable and the backend is pinged periodically; the first successful
+// ping closes the breaker again.
+//
+// Deletes and invalidations issued while open are lost, so entries may be
+// stale for up to their TTL after the cache comes back.
+type CircuitBreaker struct {
+	next          Manager
+	threshold     int
+	probeInterval time.Duration
+	logger        *zap.Logger
+
+	mu       sync.Mutex
+	state    State
+	failures int
+}
+
+func NewCircuitBreaker(next Manager, opts BreakerOptions) *CircuitBreaker {
+	threshold := opts.Threshold
+	if threshold <= 0 {
+		threshold = defaultBreakerThreshold
+	}
+	probeInterval := opts.ProbeInterval
+	if probeInterval <= 0 {
+		probeInterval = defaultBreakerProbeInterval
+	}
+
+	return &CircuitBreaker{
+		next:          next,
+		threshold:     threshold,
+		probeInterval: probeInterval,
+		logger:        logger.GetLogger().With(zap.String("component", "cache-breaker")),
+		state:         StateClosed,
+	}
+}
+
+// State returns the current breaker state
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Trip opens the breaker immediately, e.g. when the backend was unreachable
+// at startup
+func (b *CircuitBreaker) Trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.open()
+}
+
+// open must be called with mu held
+func (b *CircuitBreaker) open() {
+	if b.state == StateOpen {
+		return
+	}
+
+	b.logger.Warn("Cache circuit breaker opened, bypassing cache",
+		zap.Int("failures", b.failures),
+		zap.Duration("probe_interval", b.probeInterval),
+	)
+	b.state = StateOpen
+	go b.probe()
+}
+
+func (b *CircuitBreaker) probe() {
+	ticker := time.NewTicker(b.probeInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), b.probeInterval)
+		err := b.next.Ping(ctx)
+		cancel()
+
+		if err != nil {
+			b.logger.Debug("Cache probe failed", zap.Error(err))
+			continue
+		}
+
+		b.mu.Lock()
+		b.state = StateClosed
+		b.failures = 0
+		b.mu.Unlock()
+
+		b.logger.Info("Cache circuit breaker closed, cache is available again")
+		return
+	}
+}
+
+func (b *CircuitBreaker) allow() bool {
+	return b.State() == StateClosed
+}
+
+// record updates the failure count from the outcome of a call and passes the
+// error through
+func (b *CircuitBreaker) record(err error) error {
+	failed := isBackendFailure(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.failures = 0
+		return err
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.open()
+	}
+
+	return err
+}
+
+// isBackendFailure reports whether err means the backend itself is in trouble,
+// as opposed to a miss or a value that couldn't be (un)marshalled
+func isBackendFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if IsNotFound(err) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var unsupportedTypeErr *json.UnsupportedTypeError
+	var unsupportedValueErr *json.UnsupportedValueError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) ||
+		errors.As(err, &unsupportedTypeErr) || errors.As(err, &unsupportedValueErr) {
+		return false
+	}
+
+	return true
+}
+
+func (b *CircuitBreaker) Get(ctx context.Context, key string, value interface{}) error {
+	if !b.allow() {
+		return ErrUnavailable
+	}
+	return b.record(b.next.Get(ctx, key, value))
+}
+
+func (b *CircuitBreaker) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	if !b.allow() {
+		return ErrUnavailable
+	}
+	return b.record(b.next.Set(ctx, key, value, expiration, tags...))
+}
+
+func (b *CircuitBreaker) Delete(ctx context.Context, key string) error {
+	if !b.allow() {
+		return ErrUnavailable
+	}
+	return b.record(b.next.Delete(ctx, key))
+}
+
+func (b *CircuitBreaker) SetDefault(ctx context.Context, key string, value interface{}, tags ...string) error {
+	if !b.allow() {
+		return ErrUnavailable
+	}
+	return b.record(b.next.SetDefault(ctx, key, value, tags...))
+}
+
+func (b *CircuitBreaker) Invalidate(ctx context.Context, tags ...string) error {
+	if !b.allow() {
+		return ErrUnavailable
+	}
+	return b.record(b.next.Invalidate(ctx, tags...))
+}
+
+func (b *CircuitBreaker) Keys() KeyBuilder {
+	return b.next.Keys()
+}
+
+func (b *CircuitBreaker) MGet(ctx context.Context, keys []string) (Values, error) {
+	if !b.allow() {
+		return nil, ErrUnavailable
+	}
+	values, err := b.next.MGet(ctx, keys)
+	return values, b.record(err)
+}
+
+func (b *CircuitBreaker) MSet(ctx context.Context, entries []Entry) error {
+	if !b.allow() {
+		return ErrUnavailable
+	}
+	return b.record(b.next.MSet(ctx, entries))
+}
+
+func (b *CircuitBreaker) Exists(ctx context.Context, key string) (bool, error) {
+	if !b.allow() {
+		return false, ErrUnavailable
+	}
+	exists, err := b.next.Exists(ctx, key)
+	return exists, b.record(err)
+}
+
+func (b *CircuitBreaker) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if !b.allow() {
+		return 0, ErrUnavailable
+	}
+	ttl, err := b.next.TTL(ctx, key)
+	return ttl, b.record(err)
+}
+
+func (b *CircuitBreaker) Increment(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error) {
+	if !b.allow() {
+		return 0, ErrUnavailable
+	}
+	value, err := b.next.Increment(ctx, key, delta, expiration)
+	return value, b.record(err)
+}
+
+// Ping always reaches the backend, regardless of the breaker state
+func (b *CircuitBreaker) Ping(ctx context.Context) error {
+	return b.next.Ping(ctx)
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var errBackend = errors.New("connection refused")
+
+// fakeManager fails Get with the queued errors and Ping while pingErr is set.
+// Other methods aren't used by these tests.
+type fakeManager struct {
+	Manager
+
+	mu      sync.Mutex
+	getErrs []error
+	gets    int
+	pingErr error
+}
+
+func (m *fakeManager) Get(ctx context.Context, key string, value interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gets++
+	if len(m.getErrs) == 0 {
+		return nil
+	}
+	err := m.getErrs[0]
+	m.getErrs = m.getErrs[1:]
+	return err
+}
+
+func (m *fakeManager) Ping(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pingErr
+}
+
+func (m *fakeManager) setPingErr(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pingErr = err
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	tests := []struct {
+		name string
+		errs []error
+		want State
+	}{
+		{name: "no failures", errs: []error{nil, nil, nil}, want: StateClosed},
+		{name: "failures below threshold", errs: []error{errBackend, errBackend}, want: StateClosed},
+		{name: "consecutive failures", errs: []error{errBackend, errBackend, errBackend}, want: StateOpen},
+		{name: "success resets the count", errs: []error{errBackend, errBackend, nil, errBackend, errBackend}, want: StateClosed},
+		{name: "misses aren't failures", errs: []error{redis.Nil, redis.Nil, redis.Nil}, want: StateClosed},
+		{name: "canceled calls aren't failures", errs: []error{context.Canceled, context.Canceled, context.Canceled}, want: StateClosed},
+		{name: "timeouts are failures", errs: []error{context.DeadlineExceeded, context.DeadlineExceeded, context.DeadlineExceeded}, want: StateOpen},
+		{
+			name: "bad values aren't failures",
+			errs: []error{&json.SyntaxError{}, fmt.Errorf("decode: %w", &json.UnmarshalTypeError{}), &json.UnsupportedTypeError{}},
+			want: StateClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &fakeManager{getErrs: tt.errs, pingErr: errBackend}
+			b := NewCircuitBreaker(next, BreakerOptions{Threshold: 3, ProbeInterval: time.Hour})
+
+			for _, want := range tt.errs {
+				if err := b.Get(context.Background(), "key", nil); !errors.Is(err, want) {
+					t.Fatalf("Get() error = %v, want %v", err, want)
+				}
+			}
+			if got := b.State(); got != tt.want {
+				t.Errorf("State() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerOpenFailsFast(t *testing.T) {
+	next := &fakeManager{pingErr: errBackend}
+	b := NewCircuitBreaker(next, BreakerOptions{ProbeInterval: time.Hour})
+	b.Trip()
+
+	if err := b.Get(context.Background(), "key", nil); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Get() error = %v, want %v", err, ErrUnavailable)
+	}
+	if next.gets != 0 {
+		t.Errorf("backend called %d times while open, want 0", next.gets)
+	}
+	if err := b.Ping(context.Background()); !errors.Is(err, errBackend) {
+		t.Errorf("Ping() error = %v, want it to reach the backend", err)
+	}
+}
+
+func TestCircuitBreakerCloses(t *testing.T) {
+	next := &fakeManager{pingErr: errBackend}
+	b := NewCircuitBreaker(next, BreakerOptions{ProbeInterval: 5 * time.Millisecond})
+	b.Trip()
+
+	// Failed probes keep it open
+	time.Sleep(30 * time.Millisecond)
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() = %v while the backend is down, want %v", got, StateOpen)
+	}
+
+	next.setPingErr(nil)
+	deadline := time.Now().Add(time.Second)
+	for b.State() != StateClosed {
+		if time.Now().After(deadline) {
+			t.Fatal("breaker didn't close after the backend came back")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := b.Get(context.Background(), "key", nil); err != nil {
+		t.Errorf("Get() error = %v after closing, want nil", err)
+	}
+}
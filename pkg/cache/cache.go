@@ -3,7 +3,9 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"example/pkg/logger"
+	"fmt"
 	"time"
 
 	"github.com/eko/gocache/lib/v4/cache"
@@ -13,33 +15,107 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// defaultExpiration is used when no default TTL is configured
+	defaultExpiration = 1 * time.Hour
+	// tagExpiration matches the lifetime gocache gives the tag sets it writes
+	tagExpiration = 720 * time.Hour
+)
+
 // Manager defines the interface for cache operations
 type Manager interface {
 	Get(ctx context.Context, key string, value interface{}) error
-	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	// Set stores value with the given expiration, spread by the TTL jitter.
+	// SetDefault uses the TTL configured for the key's prefix instead.
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error
 	Delete(ctx context.Context, key string) error
-	SetDefault(ctx context.Context, key string, value interface{}) error
+	SetDefault(ctx context.Context, key string, value interface{}, tags ...string) error
+	// Invalidate removes every entry that was written with any of the tags
+	Invalidate(ctx context.Context, tags ...string) error
+	// Keys returns the builder used to namespace keys and tags for this cache
+	Keys() KeyBuilder
+
+	// MGet fetches several keys in a single pipelined round trip. Keys that
+	// are not cached are simply absent from the returned Values.
+	MGet(ctx context.Context, keys []string) (Values, error)
+	// MSet writes several entries in a single pipelined round trip.
+	MSet(ctx context.Context, entries []Entry) error
+	Exists(ctx context.Context, key string) (bool, error)
+	// TTL returns the remaining time to live of a key, or NoExpiration if the
+	// key is persistent. A missing key yields a not found error like Get.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Increment atomically adds delta to the integer stored at key and returns
+	// the new value. A positive expiration is applied if the key has none yet,
+	// such as when the increment created it.
+	Increment(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error)
+	// Ping checks that the cache backend is reachable
+	Ping(ctx context.Context) error
+}
+
+// NoExpiration is returned by TTL for keys without an expiration.
+const NoExpiration time.Duration = -1
+
+// IsNotFound reports whether err is a cache miss
+func IsNotFound(err error) bool {
+	return errors.Is(err, &store.NotFound{}) || errors.Is(err, redis.Nil)
+}
+
+// Entry is a single key/value pair written by MSet. A zero Expiration uses
+// the TTL configured for the key.
+type Entry struct {
+	Key        string
+	Value      interface{}
+	Expiration time.Duration
+	Tags       []string
+}
+
+// Values holds the raw cached payloads returned by MGet, keyed by cache key.
+type Values map[string]string
+
+// Decode unmarshals the payload cached under key into value and reports
+// whether the key was present at all.
+func (v Values) Decode(key string, value interface{}) (bool, error) {
+	raw, ok := v[key]
+	if !ok {
+		return false, nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), value); err != nil {
+		return true, err
+	}
+
+	return true, nil
 }
 
 type manager struct {
 	cache  *cache.Cache[any]
+	client redis.UniversalClient
+	keys   KeyBuilder
+	ttls   TTLPolicy
 	logger *zap.Logger
 }
 
-func NewCacheManager(redisClient *redis.Client) Manager {
+func NewCacheManager(redisClient redis.UniversalClient, keys KeyBuilder, ttls TTLPolicy) Manager {
 	log := logger.GetLogger().With(zap.String("component", "cache-manager"))
 
 	redisStore := redisstore.NewRedis(redisClient,
-		store.WithExpiration(1*time.Hour),
+		store.WithExpiration(ttls.defaultTTL),
 	)
 	cacheManager := cache.New[any](redisStore)
 
 	return &manager{
 		cache:  cacheManager,
+		client: redisClient,
+		keys:   keys,
+		ttls:   ttls,
 		logger: log,
 	}
 }
 
+func (cm *manager) Keys() KeyBuilder {
+	return cm.keys
+}
+
 func (cm *manager) Get(ctx context.Context, key string, value interface{}) error {
 	cm.logger.Debug("Getting value from cache", zap.String("key", key))
 
@@ -76,10 +152,12 @@ func (cm *manager) Get(ctx context.Context, key string, value interface{}) error
 	return nil
 }
 
-func (cm *manager) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+func (cm *manager) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	expiration = cm.ttls.Jitter(expiration)
 	cm.logger.Debug("Setting value in cache",
 		zap.String("key", key),
 		zap.Duration("expiration", expiration),
+		zap.Strings("tags", tags),
 	)
 
 	// Marshal value to JSON string
@@ -89,7 +167,7 @@ func (cm *manager) Set(ctx context.Context, key string, value interface{}, expir
 		return err
 	}
 
-	if err := cm.cache.Set(ctx, key, string(jsonBytes), store.WithExpiration(expiration)); err != nil {
+	if err := cm.cache.Set(ctx, key, string(jsonBytes), store.WithExpiration(expiration), store.WithTags(tags)); err != nil {
 		cm.logger.Error("Failed to set value in cache", zap.Error(err))
 		return err
 	}
@@ -108,9 +186,12 @@ func (cm *manager) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
-func (cm *manager) SetDefault(ctx context.Context, key string, value interface{}) error {
+func (cm *manager) SetDefault(ctx context.Context, key string, value interface{}, tags ...string) error {
+	expiration := cm.ttls.For(cm.keys.Trim(key))
 	cm.logger.Debug("Setting value in cache with default expiration",
 		zap.String("key", key),
+		zap.Duration("expiration", expiration),
+		zap.Strings("tags", tags),
 	)
 
 	// Marshal value to JSON string
@@ -120,10 +201,168 @@ func (cm *manager) SetDefault(ctx context.Context, key string, value interface{}
 		return err
 	}
 
-	if err := cm.cache.Set(ctx, key, string(jsonBytes)); err != nil {
+	if err := cm.cache.Set(ctx, key, string(jsonBytes), store.WithExpiration(expiration), store.WithTags(tags)); err != nil {
 		cm.logger.Error("Failed to set value in cache", zap.Error(err))
 		return err
 	}
 
 	return nil
 }
+
+func (cm *manager) Invalidate(ctx context.Context, tags ...string) error {
+	cm.logger.Debug("Invalidating cache tags", zap.Strings("tags", tags))
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if err := cm.cache.Invalidate(ctx, store.WithInvalidateTags(tags)); err != nil {
+		cm.logger.Error("Failed to invalidate cache tags", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (cm *manager) MGet(ctx context.Context, keys []string) (Values, error) {
+	cm.logger.Debug("Getting values from cache", zap.Strings("keys", keys))
+
+	values := make(Values, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	// GETs are pipelined rather than sent as a single MGET so that keys may
+	// live in different hash slots.
+	pipe := cm.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Get(ctx, key)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		cm.logger.Error("Failed to get values from cache", zap.Error(err))
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		value, err := cmd.Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			cm.logger.Error("Failed to get value from cache", zap.String("key", keys[i]), zap.Error(err))
+			return nil, err
+		}
+		values[keys[i]] = value
+	}
+
+	cm.logger.Debug("Fetched values from cache",
+		zap.Int("requested", len(keys)),
+		zap.Int("hits", len(values)),
+	)
+
+	return values, nil
+}
+
+func (cm *manager) MSet(ctx context.Context, entries []Entry) error {
+	cm.logger.Debug("Setting values in cache", zap.Int("count", len(entries)))
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	pipe := cm.client.Pipeline()
+	for _, entry := range entries {
+		// Marshal value to JSON string
+		jsonBytes, err := json.Marshal(entry.Value)
+		if err != nil {
+			cm.logger.Error("Failed to marshal value for caching", zap.String("key", entry.Key), zap.Error(err))
+			return err
+		}
+
+		expiration := cm.ttls.Jitter(entry.Expiration)
+		if entry.Expiration == 0 {
+			expiration = cm.ttls.For(cm.keys.Trim(entry.Key))
+		}
+		pipe.Set(ctx, entry.Key, string(jsonBytes), expiration)
+
+		// Mirror the tag bookkeeping gocache does for single sets
+		for _, tag := range entry.Tags {
+			tagKey := fmt.Sprintf(redisstore.RedisTagPattern, tag)
+			pipe.SAdd(ctx, tagKey, entry.Key)
+			pipe.Expire(ctx, tagKey, tagExpiration)
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		cm.logger.Error("Failed to set values in cache", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (cm *manager) Exists(ctx context.Context, key string) (bool, error) {
+	cm.logger.Debug("Checking key in cache", zap.String("key", key))
+
+	count, err := cm.client.Exists(ctx, key).Result()
+	if err != nil {
+		cm.logger.Error("Failed to check key in cache", zap.Error(err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (cm *manager) TTL(ctx context.Context, key string) (time.Duration, error) {
+	cm.logger.Debug("Getting TTL from cache", zap.String("key", key))
+
+	ttl, err := cm.client.TTL(ctx, key).Result()
+	if err != nil {
+		cm.logger.Error("Failed to get TTL from cache", zap.Error(err))
+		return 0, err
+	}
+
+	// Redis reports -2 for missing keys and -1 for keys without expiration
+	switch ttl {
+	case -2:
+		return 0, store.NotFoundWithCause(redis.Nil)
+	case -1:
+		return NoExpiration, nil
+	}
+
+	return ttl, nil
+}
+
+// incrementScript adds ARGV[1] to KEYS[1] and, if the key has no expiration
+// yet and ARGV[2] is positive, expires it after ARGV[2] milliseconds. Running
+// both in one script means a counter can't be left without its expiration,
+// whatever the delta and even if the client goes away in between.
+var incrementScript = redis.NewScript(`
+local value = redis.call('INCRBY', KEYS[1], ARGV[1])
+local expiration = tonumber(ARGV[2])
+if expiration > 0 and redis.call('PTTL', KEYS[1]) == -1 then
+	redis.call('PEXPIRE', KEYS[1], expiration)
+end
+return value
+`)
+
+func (cm *manager) Increment(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error) {
+	cm.logger.Debug("Incrementing value in cache",
+		zap.String("key", key),
+		zap.Int64("delta", delta),
+	)
+
+	value, err := incrementScript.Run(ctx, cm.client, []string{key}, delta, expiration.Milliseconds()).Int64()
+	if err != nil {
+		cm.logger.Error("Failed to increment value in cache", zap.Error(err))
+		return 0, err
+	}
+
+	return value, nil
+}
+
+func (cm *manager) Ping(ctx context.Context) error {
+	return cm.client.Ping(ctx).Err()
+}
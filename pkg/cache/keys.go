@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+)
+
+const keySeparator = ":"
+
+// KeyBuilder builds cache keys and tags under a common namespace made of the
+// application name and a cache schema version, e.g. "example:v1:user:42".
+// Bumping the schema version on deploy makes every previously written entry
+// unreachable, so they simply age out.
+type KeyBuilder struct {
+	prefix string
+}
+
+func NewKeyBuilder(appName string, schemaVersion int) KeyBuilder {
+	parts := []string{fmt.Sprintf("v%d", schemaVersion)}
+	if appName != "" {
+		parts = append([]string{appName}, parts...)
+	}
+
+	return KeyBuilder{
+		prefix: strings.Join(parts, keySeparator),
+	}
+}
+
+// Key joins parts into a namespaced cache key
+func (b KeyBuilder) Key(parts ...interface{}) string {
+	return b.join("", parts)
+}
+
+// Tag joins parts into a namespaced tag. Entries written with a tag can be
+// dropped together through Manager.Invalidate.
+func (b KeyBuilder) Tag(parts ...interface{}) string {
+	return b.join("tag", parts)
+}
+
+// Prefix returns the namespace shared by every key and tag
+func (b KeyBuilder) Prefix() string {
+	return b.prefix
+}
+
+// Trim strips the namespace from a key built by Key
+func (b KeyBuilder) Trim(key string) string {
+	return strings.TrimPrefix(key, b.prefix+keySeparator)
+}
+
+func (b KeyBuilder) join(kind string, parts []interface{}) string {
+	var sb strings.Builder
+	sb.WriteString(b.prefix)
+	if kind != "" {
+		sb.WriteString(keySeparator)
+		sb.WriteString(kind)
+	}
+	for _, part := range parts {
+		sb.WriteString(keySeparator)
+		sb.WriteString(fmt.Sprint(part))
+	}
+	return sb.String()
+}
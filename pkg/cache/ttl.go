@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+)
+
+// prefixTTL is a TTL override for keys under a given prefix
+type prefixTTL struct {
+	prefix string
+	ttl    time.Duration
+}
+
+// TTLOptions configure a TTLPolicy
+type TTLOptions struct {
+	// Default applies to keys matching no prefix; zero means one hour
+	Default time.Duration
+	// Prefixes overrides Default per key prefix as prefix=duration pairs,
+	// e.g. "user:email=30m". The longest matching prefix wins.
+	Prefixes []string
+	// Jitter is the fraction of a TTL, between 0 and 1, that is randomly
+	// added or removed on every write
+	Jitter float64
+}
+
+// TTLPolicy decides how long entries live. Keys get the TTL of their longest
+// configured prefix, or the default TTL, and every TTL is spread by a random
+// jitter so that entries written together don't all expire together.
+type TTLPolicy struct {
+	defaultTTL time.Duration
+	prefixes   []prefixTTL
+	jitter     float64
+}
+
+func NewTTLPolicy(opts TTLOptions) (TTLPolicy, error) {
+	policy := TTLPolicy{
+		defaultTTL: opts.Default,
+		jitter:     opts.Jitter,
+	}
+
+	if policy.defaultTTL <= 0 {
+		policy.defaultTTL = defaultExpiration
+	}
+
+	if policy.jitter < 0 || policy.jitter >= 1 {
+		return TTLPolicy{}, fmt.Errorf("cache TTL jitter must be in [0, 1), got %v", policy.jitter)
+	}
+
+	for _, pair := range opts.Prefixes {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		prefix, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return TTLPolicy{}, fmt.Errorf("invalid cache TTL %q, expected prefix=duration", pair)
+		}
+
+		ttl, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || ttl <= 0 {
+			return TTLPolicy{}, fmt.Errorf("invalid cache TTL %q: duration must be positive", pair)
+		}
+
+		policy.prefixes = append(policy.prefixes, prefixTTL{
+			prefix: strings.Trim(strings.TrimSpace(prefix), keySeparator),
+			ttl:    ttl,
+		})
+	}
+
+	// Longest prefixes first so the most specific override wins
+	sort.Slice(policy.prefixes, func(i, j int) bool {
+		return len(policy.prefixes[i].prefix) > len(policy.prefixes[j].prefix)
+	})
+
+	return policy, nil
+}
+
+// For returns the jittered TTL for a key, given without its namespace
+func (p TTLPolicy) For(key string) time.Duration {
+	for _, override := range p.prefixes {
+		if key == override.prefix || strings.HasPrefix(key, override.prefix+keySeparator) {
+			return p.Jitter(override.ttl)
+		}
+	}
+
+	return p.Jitter(p.defaultTTL)
+}
+
+// Jitter spreads ttl randomly by up to the configured fraction either way
+func (p TTLPolicy) Jitter(ttl time.Duration) time.Duration {
+	if p.jitter == 0 || ttl <= 0 {
+		return ttl
+	}
+
+	spread := float64(ttl) * p.jitter
+	return ttl + time.Duration(spread*(2*rand.Float64()-1))
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLPolicyFor(t *testing.T) {
+	policy, err := NewTTLPolicy(TTLOptions{
+		Default:  10 * time.Minute,
+		Prefixes: []string{"user=1h", " user:email = 30m ", "user:email:verified:=5m", "", "role=2h"},
+	})
+	if err != nil {
+		t.Fatalf("NewTTLPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want time.Duration
+	}{
+		{name: "exact prefix", key: "user", want: time.Hour},
+		{name: "under prefix", key: "user:42", want: time.Hour},
+		{name: "longest prefix wins", key: "user:email:abc", want: 30 * time.Minute},
+		{name: "separators are trimmed from prefixes", key: "user:email:verified:abc", want: 5 * time.Minute},
+		{name: "prefix matches whole segments only", key: "users:list", want: 10 * time.Minute},
+		{name: "other prefix", key: "role:admin", want: 2 * time.Hour},
+		{name: "no prefix", key: "org:1", want: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.For(tt.key); got != tt.want {
+				t.Errorf("For(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTTLPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        TTLOptions
+		wantErr     bool
+		wantDefault time.Duration
+	}{
+		{name: "zero default", opts: TTLOptions{}, wantDefault: defaultExpiration},
+		{name: "negative default", opts: TTLOptions{Default: -time.Minute}, wantDefault: defaultExpiration},
+		{name: "negative jitter", opts: TTLOptions{Jitter: -0.1}, wantErr: true},
+		{name: "jitter of one", opts: TTLOptions{Jitter: 1}, wantErr: true},
+		{name: "missing duration", opts: TTLOptions{Prefixes: []string{"user"}}, wantErr: true},
+		{name: "invalid duration", opts: TTLOptions{Prefixes: []string{"user=soon"}}, wantErr: true},
+		{name: "zero duration", opts: TTLOptions{Prefixes: []string{"user=0s"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewTTLPolicy(tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("NewTTLPolicy() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTTLPolicy() error = %v", err)
+			}
+			if got := policy.For("any"); got != tt.wantDefault {
+				t.Errorf("For() = %v, want %v", got, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestTTLPolicyJitter(t *testing.T) {
+	tests := []struct {
+		name   string
+		jitter float64
+		ttl    time.Duration
+		min    time.Duration
+		max    time.Duration
+	}{
+		{name: "no jitter", jitter: 0, ttl: time.Hour, min: time.Hour, max: time.Hour},
+		{name: "ten percent", jitter: 0.1, ttl: time.Hour, min: 54 * time.Minute, max: 66 * time.Minute},
+		{name: "zero ttl is kept", jitter: 0.5, ttl: 0, min: 0, max: 0},
+		{name: "negative ttl is kept", jitter: 0.5, ttl: -time.Second, min: -time.Second, max: -time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewTTLPolicy(TTLOptions{Jitter: tt.jitter})
+			if err != nil {
+				t.Fatalf("NewTTLPolicy() error = %v", err)
+			}
+
+			spread := false
+			for i := 0; i < 1000; i++ {
+				got := policy.Jitter(tt.ttl)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("Jitter(%v) = %v, want within [%v, %v]", tt.ttl, got, tt.min, tt.max)
+				}
+				spread = spread || got != tt.ttl
+			}
+			if wantSpread := tt.min != tt.max; spread != wantSpread {
+				t.Errorf("Jitter(%v) spread = %v, want %v", tt.ttl, spread, wantSpread)
+			}
+		})
+	}
+}
@@ -28,7 +28,8 @@ func NewPostgresDB(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 		zap.String("user", cfg.User),
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError turns unique violations into gorm.ErrDuplicatedKey
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Error("Failed to connect to database", zap.Error(err))
 		return nil, err
@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"example/internal/config"
+	"example/pkg/logger"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type logMailer struct {
+	from   string
+	logger *zap.Logger
+}
+
+// NewLogMailer creates a mailer that only logs emails, bodies included. It
+// must not be used in production as the bodies contain secrets such as reset
+// links.
+func NewLogMailer(cfg *config.MailConfig) Mailer {
+	return &logMailer{
+		from:   cfg.From,
+		logger: logger.GetLogger().With(zap.String("component", "log-mailer")),
+	}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg Message) error {
+	if err := checkRecipient(msg.To); err != nil {
+		return err
+	}
+
+	m.logger.Info("Email",
+		zap.String("from", m.from),
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
+
+type fileMailer struct {
+	path string
+	from string
+	mu   sync.Mutex
+}
+
+// NewFileMailer creates a mailer that appends emails to a file, separated by
+// mbox "From " lines
+func NewFileMailer(cfg *config.MailConfig) (Mailer, error) {
+	if cfg.FilePath == "" {
+		return nil, errors.New("MAIL_FILE_PATH is required for the file mail driver")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.FilePath), 0o755); err != nil {
+		return nil, fmt.Errorf("create mail directory: %w", err)
+	}
+
+	return &fileMailer{
+		path: cfg.FilePath,
+		from: cfg.From,
+	}, nil
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg Message) error {
+	if err := checkRecipient(msg.To); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open mail file: %w", err)
+	}
+	defer f.Close()
+
+	now := time.Now()
+	if _, err := fmt.Fprintf(f, "From %s %s\n", m.from, now.Format(time.ANSIC)); err != nil {
+		return err
+	}
+	if _, err := f.Write(format(m.from, msg, now)); err != nil {
+		return err
+	}
+	_, err = f.WriteString("\n\n")
+	return err
+}
@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"example/internal/config"
+	"example/pkg/logger"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const defaultSMTPPort = 587
+
+type smtpMailer struct {
+	addr   string
+	host   string
+	from   string
+	auth   smtp.Auth
+	logger *zap.Logger
+}
+
+// NewSMTPMailer creates a mailer delivering through an SMTP server. STARTTLS
+// is used whenever the server offers it, and PLAIN auth when a username is
+// configured.
+func NewSMTPMailer(cfg *config.MailConfig) (Mailer, error) {
+	if cfg.SMTPHost == "" {
+		return nil, errors.New("MAIL_SMTP_HOST is required for the smtp mail driver")
+	}
+
+	port := cfg.SMTPPort
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+
+	m := &smtpMailer{
+		addr:   net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(port)),
+		host:   cfg.SMTPHost,
+		from:   cfg.From,
+		logger: logger.GetLogger().With(zap.String("component", "smtp-mailer")),
+	}
+	if cfg.SMTPUsername != "" {
+		m.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return m, nil
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+	if err := checkRecipient(msg.To); err != nil {
+		return err
+	}
+
+	// net/smtp doesn't take a context, so only honour one that's already done
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, format(m.from, msg, time.Now())); err != nil {
+		m.logger.Error("Failed to send email", zap.String("subject", msg.Subject), zap.Error(err))
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	m.logger.Info("Email sent", zap.String("subject", msg.Subject))
+	return nil
+}
@@ -2,36 +2,119 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"example/internal/config"
 	"example/pkg/logger"
 	"fmt"
+	"os"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
-func NewRedisClient(cfg *config.RedisConfig) (*redis.Client, error) {
+// NewRedisClient creates a Redis client for the configured mode (standalone,
+// sentinel or cluster) and checks the connection. If the server can't be
+// reached the client is still returned along with the error, so callers may
+// decide to run without the cache; go-redis reconnects on its own once the
+// server is back.
+func NewRedisClient(cfg *config.RedisConfig) (redis.UniversalClient, error) {
 	log := logger.GetLogger().With(zap.String("component", "redis-client"))
 
+	addrs := cfg.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)}
+	}
+
+	mode := cfg.Mode
+	if mode == "" {
+		mode = config.RedisModeStandalone
+	}
+
 	log.Info("Connecting to Redis",
-		zap.String("host", cfg.Host),
-		zap.String("port", cfg.Port),
+		zap.String("mode", mode),
+		zap.Strings("addrs", addrs),
 		zap.Int("database", cfg.DB),
+		zap.Bool("tls", cfg.TLSEnabled),
 	)
 
-	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
-		Password: cfg.Password,
-		DB:       cfg.DB,
-	})
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		log.Error("Failed to load Redis TLS configuration", zap.Error(err))
+		return nil, err
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:            addrs,
+		DB:               cfg.DB,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		MasterName:       cfg.MasterName,
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword,
+		TLSConfig:        tlsConfig,
+	}
+
+	var client redis.UniversalClient
+	switch mode {
+	case config.RedisModeStandalone:
+		client = redis.NewClient(opts.Simple())
+	case config.RedisModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, errors.New("REDIS_MASTER_NAME is required in sentinel mode")
+		}
+		client = redis.NewFailoverClient(opts.Failover())
+	case config.RedisModeCluster:
+		client = redis.NewClusterClient(opts.Cluster())
+	default:
+		return nil, fmt.Errorf("unknown Redis mode %q", mode)
+	}
 
 	// Test connection
-	_, err := client.Ping(context.Background()).Result()
+	_, err = client.Ping(context.Background()).Result()
 	if err != nil {
 		log.Error("Failed to connect to Redis", zap.Error(err))
-		return nil, err
+		return client, err
 	}
 
 	log.Info("Successfully connected to Redis")
 	return client, nil
 }
+
+// newTLSConfig builds the TLS settings for the Redis connection, or returns nil
+// when TLS is disabled
+func newTLSConfig(cfg *config.RedisConfig) (*tls.Config, error) {
+	if !cfg.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.TLSServerName,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+
+	if cfg.TLSCAFile != "" {
+		caCert, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
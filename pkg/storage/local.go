@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"example/internal/config"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type localStore struct {
+	dir       string
+	publicURL string
+}
+
+// NewLocalStore creates a blob store writing to a directory on the local
+// filesystem. It suits development and single-instance deployments; the
+// files have to be served from PublicURL by something else, such as the API
+// itself.
+func NewLocalStore(cfg *config.StorageConfig) (BlobStore, error) {
+	if cfg.LocalDir == "" {
+		return nil, errors.New("STORAGE_LOCAL_DIR is required for the local storage driver")
+	}
+	if err := os.MkdirAll(cfg.LocalDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create storage directory: %w", err)
+	}
+
+	return &localStore{
+		dir:       cfg.LocalDir,
+		publicURL: cfg.PublicURL,
+	}, nil
+}
+
+func (s *localStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+// Put writes the blob to a temporary file first and renames it into place, so
+// readers never see a partial file
+func (s *localStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *localStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Delete removes the blob. Deleting a missing blob isn't an error.
+func (s *localStore) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *localStore) URL(key string) string {
+	return publicURL(s.publicURL, key)
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"example/internal/config"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when there is no blob under the key
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores opaque blobs under slash-separated keys, such as
+// "avatars/42/abc/64.png", and knows the public URL they are served from
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns the blob under key, or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	// URL is the public address of key. It doesn't check the key exists.
+	URL(key string) string
+}
+
+// NewBlobStore creates the blob store for the configured driver
+func NewBlobStore(cfg *config.StorageConfig) (BlobStore, error) {
+	if cfg.PublicURL == "" {
+		return nil, errors.New("STORAGE_PUBLIC_URL is required")
+	}
+
+	switch cfg.Driver {
+	case config.StorageDriverLocal, "":
+		return NewLocalStore(cfg)
+	case config.StorageDriverS3:
+		return NewS3Store(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// checkKey rejects keys that could escape the store's root
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
+
+func publicURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}